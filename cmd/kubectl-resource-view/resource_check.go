@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/writer"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// checkFailedExitCode is the exit code of the check command when a violation at or above --fail-on is found
+const checkFailedExitCode = 2
+
+var errCheckFailed = errors.New("check failed")
+
+var checkTargets = []string{"node", "pod", "cluster"}
+
+type ResourceCheckOptions struct {
+	Namespace     string
+	Targets       string
+	Targetslice   []string
+	Selector      string
+	Columns       string
+	Columnslice   []string
+	FailOn        string
+	FailOnLevel   kube.Level
+	Output        string
+	AllNamespaces bool
+
+	DiscoveryClient discovery.DiscoveryInterface
+	Client          *kube.KubeClient
+	Thresholds      kube.Thresholds
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceCheckLong = templates.LongDesc(i18n.T(`
+		Check the percentages of nodes, pods or the whole cluster against the warning and critical thresholds.
+
+		The 'resource-view check' command prints every percentage above its warning or critical threshold,
+		set with --warn, --crit or --thresholds-file, and exits with code 2 if one of them is at or above
+		the --fail-on level. Nodes and the cluster, i.e. the sum of the selected nodes, are checked for
+		cpu-req, cpu-limit, mem-req, mem-limit, gpu-req, gpu-limit, pod, and cpu-usage and mem-usage of
+		the capacity, the running pods for cpu-usage and mem-usage of their limits only, a column which
+		can not be checked on a target is refused. Only cpu-usage and mem-usage need the metrics API:
+		without it, or if the usage of a checked node or pod is unknown, they fail when selected with
+		--columns or with --target pod, and are skipped with a message otherwise. As -l selects the
+		nodes, or the pods with --target pod, it can not be used to check pods with nodes or the cluster.`))
+
+	resourceCheckExample = templates.Examples(i18n.T(`
+		# Fail if a node is above a critical threshold
+		kubectl resource-view check
+
+		# Warn if the memory requests of a node pool exceed 80%, fail if they exceed 85%
+		kubectl resource-view check --target cluster -l pool=default --columns mem-req --warn 80 --crit 85
+
+		# Fail on warnings too, checking nodes and the pods of all namespaces
+		kubectl resource-view check --target node,pod -A --fail-on warning
+		`))
+)
+
+func NewCmdResourceCheck(f cmdutil.Factory, o *ResourceCheckOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceCheckOptions{
+			IOStreams: streams,
+			Targets:   "node",
+			FailOn:    "critical",
+			Output:    "json",
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "check [--target node,pod,cluster] [-l label]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Check resource percentages against the thresholds"),
+		Long:                  resourceCheckLong,
+		Example:               resourceCheckExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			err := o.RunResourceCheck()
+			if errors.Is(err, errCheckFailed) {
+				os.Exit(checkFailedExitCode)
+			}
+			cmdutil.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&o.Targets, "target", o.Targets, "What to check [possible values: node,pod,cluster], Multiple can be specified, separated by commas")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter the nodes, or the pods for --target pod, on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2). Can not be used to check pods with nodes or the cluster")
+	cmd.Flags().StringVar(&o.Columns, "columns", o.Columns, "Percentage columns to check (default: all)[possible values: cpu-req,cpu-limit,cpu-usage,mem-req,mem-limit,mem-usage,gpu-req,gpu-limit,pod], Multiple can be specified, separated by commas")
+	cmd.Flags().StringVar(&o.FailOn, "fail-on", o.FailOn, "Exit with code 2 if a percentage is at or above this level [possible values: warning,critical]")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, check the pods across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format of the violations. One of: json|yaml|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json|custom-columns|custom-columns-file")
+	return cmd
+}
+
+func (o *ResourceCheckOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	if len(args) > 0 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.DiscoveryClient = clientset.DiscoveryClient
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = newKubeClient(config)
+	if err != nil {
+		return err
+	}
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
+}
+
+func (o *ResourceCheckOptions) Validate() error {
+	var err error
+	o.FailOnLevel, err = kube.ParseLevel(o.FailOn)
+	if err != nil {
+		return fmt.Errorf("--fail-on: %v", err)
+	}
+	if writer.IsCSVOutput(o.Output) {
+		return errors.New("--output accepts only json, yaml or a template")
+	}
+	if err := writer.ValidateOutput(o.Output); err != nil {
+		return err
+	}
+
+	o.Targetslice = strings.Split(o.Targets, ",")
+	for _, str := range o.Targetslice {
+		if !MapKeyInIntSlice(checkTargets, str) {
+			return errors.New("--target accepts only node,pod,cluster")
+		}
+	}
+	if len(o.Columns) > 0 {
+		o.Columnslice = strings.Split(o.Columns, ",")
+		for _, str := range o.Columnslice {
+			if !MapKeyInIntSlice(kube.ThresholdColumns, str) {
+				return fmt.Errorf("--columns accepts only %s", strings.Join(kube.ThresholdColumns, ","))
+			}
+		}
+	}
+
+	if o.checks("pod") {
+		for _, str := range o.Columnslice {
+			if !MapKeyInIntSlice(kube.PodColumns, str) {
+				return fmt.Errorf("--columns %s can not be checked on pods, which have only %s", str, strings.Join(kube.PodColumns, ","))
+			}
+		}
+		if len(o.Selector) > 0 && (o.checks("node") || o.checks("cluster")) {
+			return errors.New("-l selects either the nodes or the pods, check the pods separately with --target pod")
+		}
+	}
+	return nil
+}
+
+//checks reports whether target is one of the --target values
+func (o *ResourceCheckOptions) checks(target string) bool {
+	return MapKeyInIntSlice(o.Targetslice, target)
+}
+
+//checksUsage reports whether cpu-usage or mem-usage is checked
+func (o *ResourceCheckOptions) checksUsage() bool {
+	return len(o.Columnslice) == 0 || MapKeyInIntSlice(o.Columnslice, kube.ThresholdCPUUsage) || MapKeyInIntSlice(o.Columnslice, kube.ThresholdMemUsage)
+}
+
+//unknownUsages fails if the usage of names is unknown and required, and tells it is not checked otherwise
+func (o *ResourceCheckOptions) unknownUsages(kind string, names []string, required bool) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if required {
+		return fmt.Errorf("the usage of the %s %s is unknown, cpu-usage and mem-usage can not be checked", kind, strings.Join(names, ", "))
+	}
+	fmt.Fprintf(o.ErrOut, "the usage of the %s %s is unknown, checking their requests and limits only\n", kind, strings.Join(names, ", "))
+	return nil
+}
+
+func (o ResourceCheckOptions) RunResourceCheck() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	selector, err := labels.Parse(o.Selector)
+	if err != nil {
+		return err
+	}
+
+	// the usage is read for the usage columns only, which must be checked if given with --columns
+	usage := o.checksUsage()
+	required := len(o.Columnslice) > 0
+	if usage {
+		apiGroups, err := o.DiscoveryClient.ServerGroups()
+		if err != nil {
+			return err
+		}
+		if !usageAvailable(apiGroups) {
+			if required {
+				return errors.New("metrics API not available, cpu-usage and mem-usage can not be checked")
+			}
+			if o.checks("pod") {
+				return errors.New("metrics API not available, pods can not be checked")
+			}
+			fmt.Fprintln(o.ErrOut, "metrics API not available, checking requests and limits only")
+			usage = false
+		}
+	}
+
+	violations := []kube.Violation{}
+	if o.checks("node") || o.checks("cluster") {
+		var noderesources []kube.NodeResource
+		if usage {
+			noderesources, err = o.Client.GetNodeResources(ctx, "", selector)
+		} else {
+			noderesources, err = o.Client.GetNodeResourcesWithoutUsage(ctx, "", selector)
+		}
+		if err != nil {
+			return err
+		}
+		if usage {
+			if err := o.unknownUsages("nodes", kube.UnknownNodeUsages(noderesources), required); err != nil {
+				return err
+			}
+		}
+		if o.checks("node") {
+			violations = append(violations, kube.NodeViolations(noderesources, o.Thresholds, o.Columnslice, kube.LevelWarning)...)
+		}
+		if o.checks("cluster") {
+			name := "cluster"
+			if len(o.Selector) > 0 {
+				name = o.Selector
+			}
+			cluster := kube.ClusterResources(noderesources)
+			violations = append(violations, kube.ClusterViolations(name, cluster, o.Thresholds, o.Columnslice, kube.LevelWarning)...)
+		}
+	}
+	// pods are checked for their usage only, which the pods have once running
+	if o.checks("pod") {
+		podresources, err := o.Client.ListPodResources(ctx, o.Namespace, "", o.AllNamespaces, selector, fields.Everything(), []corev1.PodPhase{corev1.PodRunning}, true)
+		if err != nil {
+			return err
+		}
+		if err := o.unknownUsages("pods", kube.UnknownPodUsages(podresources), true); err != nil {
+			return err
+		}
+		violations = append(violations, kube.PodViolations(podresources, o.Thresholds, o.Columnslice, kube.LevelWarning)...)
+	}
+
+	if err := writer.ObjectWrite(o.Out, kube.ViolationList{Items: violations}, o.Output); err != nil {
+		return err
+	}
+	for _, v := range violations {
+		if level, _ := kube.ParseLevel(v.Level); level >= o.FailOnLevel {
+			return errCheckFailed
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResourceCheckValidate(t *testing.T) {
+	tests := []struct {
+		targets  string
+		columns  string
+		selector string
+		wantErr  string
+	}{
+		{targets: "node"},
+		{targets: "node", columns: "cpu-usage,mem-req"},
+		{targets: "cluster", columns: "mem-usage", selector: "pool=default"},
+		{targets: "pod"},
+		{targets: "pod", columns: "cpu-usage,mem-usage", selector: "app=web"},
+		{targets: "node,pod", columns: "cpu-usage"},
+		// a column which pods do not have would check nothing
+		{targets: "pod", columns: "cpu-req", wantErr: "--columns cpu-req can not be checked on pods"},
+		{targets: "node,pod", columns: "cpu-usage,pod", wantErr: "--columns pod can not be checked on pods"},
+		// -l would select both the nodes and the pods
+		{targets: "node,pod", selector: "pool=default", wantErr: "-l selects either the nodes or the pods"},
+		{targets: "cluster,pod", selector: "pool=default", wantErr: "-l selects either the nodes or the pods"},
+		{targets: "namespace", wantErr: "--target accepts only"},
+		{targets: "node", columns: "disk", wantErr: "--columns accepts only"},
+	}
+	for _, test := range tests {
+		o := &ResourceCheckOptions{Targets: test.targets, Columns: test.columns, Selector: test.selector, FailOn: "critical", Output: "json"}
+		err := o.Validate()
+		if len(test.wantErr) == 0 {
+			if err != nil {
+				t.Errorf("--target %s --columns %q -l %q: %v", test.targets, test.columns, test.selector, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("--target %s --columns %q -l %q: got error %v, want %q", test.targets, test.columns, test.selector, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/writer"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type ResourceClusterOptions struct {
+	ResourceType      string
+	ResourceTypeslice []string
+	Resources         string
+	Selector          string
+	Output            string
+	NoFormat          bool
+	NoUsage           bool
+
+	DiscoveryClient discovery.DiscoveryInterface
+	Client          *kube.KubeClient
+	Thresholds      kube.Thresholds
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceClusterLong = templates.LongDesc(i18n.T(`
+		Display resource (cpu/memory/gpu/podcount) usage of the whole cluster.
+
+		The 'resource-view cluster' command sums up the capacity, requests, limits and usage
+		of all nodes. The overcommit ratio is the sum of the limits divided by the capacity.
+		Without the metrics API, or with --no-usage, the usage is shown as n/a, as is the
+		usage of the cluster if it is unknown for one of the nodes, e.g. a node just joined.`))
+
+	resourceClusterExample = templates.Examples(i18n.T(`
+		# Show the resource summary of the cluster
+		kubectl resource-view cluster
+
+		# Show the resource summary of the nodes defined by label node-role.kubernetes.io/worker
+		kubectl resource-view cluster -l node-role.kubernetes.io/worker
+
+		# Show the allocation of AMD GPUs of the cluster
+		kubectl resource-view cluster -t pod --resource amd.com/gpu
+
+		# Show the cpu and memory summary of the cluster in json format
+		kubectl resource-view cluster -t cpu,memory -o json
+		`))
+)
+
+func NewCmdResourceCluster(f cmdutil.Factory, o *ResourceClusterOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceClusterOptions{
+			IOStreams: streams,
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "cluster [-l label]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Display resource (cpu/memory/gpu/podcount) usage of the cluster"),
+		Long:                  resourceClusterLong,
+		Example:               resourceClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunResourceCluster())
+		},
+	}
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter nodes on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVarP(&o.ResourceType, "type", "t", o.ResourceType, "Type information hierarchically (default: All Type)[possible values: cpu,memory,pod,gpu], Multiple can be specified, separated by commas")
+	cmd.Flags().StringVar(&o.Resources, "resource", o.Resources, "Extended resources to show the requests, limits and capacity of, e.g. amd.com/gpu,hugepages-2Mi, Multiple can be specified, separated by commas")
+	cmd.Flags().BoolVar(&o.NoFormat, "no-format", o.NoFormat, "If present, print output without format table")
+	cmd.Flags().BoolVar(&o.NoUsage, "no-usage", o.NoUsage, "If present, skip the metrics API and show only the requests, limits and capacity, with the usage as n/a. Used automatically when the metrics API is not available")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml|csv|tsv|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json|custom-columns|custom-columns-file")
+	return cmd
+}
+
+func (o *ResourceClusterOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.DiscoveryClient = clientset.DiscoveryClient
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = newKubeClient(config)
+	if err != nil {
+		return err
+	}
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
+}
+
+func (o *ResourceClusterOptions) Validate() error {
+	if len(o.Output) > 0 {
+		if err := writer.ValidateOutput(o.Output); err != nil {
+			return err
+		}
+	}
+
+	o.ResourceTypeslice = strings.Split(o.ResourceType, ",")
+	if len(o.ResourceType) > 0 {
+		for _, str := range o.ResourceTypeslice {
+			if !MapKeyInIntSlice(nodeResourceType, str) {
+				return errors.New("--type accepts only cpu,memory,pod,gpu")
+			}
+		}
+	}
+	resources, err := parseExtendedResources(o.Resources)
+	if err != nil {
+		return err
+	}
+	o.ResourceTypeslice = append(o.ResourceTypeslice, resources...)
+	return nil
+}
+
+func (o ResourceClusterOptions) RunResourceCluster() error {
+	var err error
+	selector := labels.Everything()
+	if len(o.Selector) > 0 {
+		selector, err = labels.Parse(o.Selector)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !o.NoUsage {
+		apiGroups, err := o.DiscoveryClient.ServerGroups()
+		if err != nil {
+			return err
+		}
+		if !usageAvailable(apiGroups) {
+			fmt.Fprintln(o.ErrOut, "metrics API not available, showing requests and limits only")
+			o.NoUsage = true
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var noderesources []kube.NodeResource
+	if o.NoUsage {
+		noderesources, err = o.Client.GetNodeResourcesWithoutUsage(ctx, "", selector)
+	} else {
+		noderesources, err = o.Client.GetNodeResources(ctx, "", selector)
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errors.New("operation timed out - too many nodes or slow API response")
+		}
+		return err
+	}
+	data := kube.ClusterResources(noderesources)
+
+	if writer.IsCSVOutput(o.Output) {
+		return writer.ClusterCSVWrite(o.Out, data, o.ResourceTypeslice, o.Output)
+	}
+	if len(o.Output) > 0 {
+		return writer.ObjectWrite(o.Out, data, o.Output)
+	}
+	writer.ClusterWrite(o.Out, data, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+	return nil
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/writer"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type ResourceDiffOptions struct {
+	Before   string
+	After    string
+	All      bool
+	Output   string
+	NoFormat bool
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceDiffLong = templates.LongDesc(i18n.T(`
+		Compare two snapshots saved by 'resource-view snapshot save'.
+
+		The 'resource-view diff' command shows the changes of the pod count, requests, limits and usage
+		of every node, and of every namespace summed up over its pods. A value is shown as in the second
+		snapshot, followed by its difference to the first one. Nodes and namespaces found in one snapshot
+		only are added or removed, an unknown usage is not compared.
+
+		By default only the nodes and namespaces which changed are shown, --all shows them all. A node or
+		namespace is changed when its pod count, requests or limits changed: the usage differs between
+		almost any two snapshots, its changes are shown but do not make a node or namespace changed.`))
+
+	resourceDiffExample = templates.Examples(i18n.T(`
+		# Compare the resources of the cluster before and after a release
+		kubectl resource-view snapshot save before.json
+		kubectl resource-view snapshot save after.json
+		kubectl resource-view diff before.json after.json
+
+		# Show the unchanged nodes and namespaces too
+		kubectl resource-view diff before.json after.json --all
+
+		# Show the changes in json format
+		kubectl resource-view diff before.json after.json -o json
+		`))
+)
+
+func NewCmdResourceDiff(f cmdutil.Factory, o *ResourceDiffOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceDiffOptions{
+			IOStreams: streams,
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "diff BEFORE AFTER",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Compare two snapshots of the resources of nodes and pods"),
+		Long:                  resourceDiffLong,
+		Example:               resourceDiffExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunResourceDiff())
+		},
+	}
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If present, show the nodes and namespaces which did not change too")
+	cmd.Flags().BoolVar(&o.NoFormat, "no-format", o.NoFormat, "If present, print output without format table")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json")
+	return cmd
+}
+
+func (o *ResourceDiffOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+	o.Before, o.After = args[0], args[1]
+	return nil
+}
+
+func (o *ResourceDiffOptions) Validate() error {
+	if len(o.Output) > 0 {
+		if writer.IsCSVOutput(o.Output) {
+			return errors.New("--output accepts only json, yaml or a template")
+		}
+		if err := writer.ValidateOutput(o.Output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o ResourceDiffOptions) RunResourceDiff() error {
+	before, err := kube.LoadSnapshot(o.Before)
+	if err != nil {
+		return err
+	}
+	after, err := kube.LoadSnapshot(o.After)
+	if err != nil {
+		return err
+	}
+
+	diff := kube.DiffSnapshots(before, after)
+	if !o.All {
+		diff.Nodes = changedDeltas(diff.Nodes)
+		diff.Namespaces = changedDeltas(diff.Namespaces)
+	}
+
+	if len(o.Output) > 0 {
+		return writer.ObjectWrite(o.Out, diff, o.Output)
+	}
+	if len(diff.Nodes) == 0 && len(diff.Namespaces) == 0 {
+		fmt.Fprintln(o.ErrOut, "No changes found")
+		return nil
+	}
+	writer.SnapshotDiffWrite(o.Out, diff, o.NoFormat)
+	return nil
+}
+
+//changedDeltas returns the deltas of the nodes or namespaces which were added, removed or changed
+func changedDeltas(deltas []kube.ResourceDelta) []kube.ResourceDelta {
+	var changed []kube.ResourceDelta
+	for _, d := range deltas {
+		if d.Status != kube.DiffUnchanged {
+			changed = append(changed, d)
+		}
+	}
+	return changed
+}
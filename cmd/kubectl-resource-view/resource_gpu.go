@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/writer"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type ResourceGPUOptions struct {
+	ResourceName string
+	Selector     string
+	Output       string
+	NoFormat     bool
+
+	Client     *kube.KubeClient
+	Thresholds kube.Thresholds
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceGPULong = templates.LongDesc(i18n.T(`
+		Display the allocation of the NVIDIA GPUs of nodes.
+
+		The 'resource-view gpu' command discovers every resource of the nvidia.com domain on the nodes, full GPUs,
+		MIG profiles such as nvidia.com/mig-1g.5gb and time-sliced nvidia.com/gpu.shared, and shows
+		their requests and limits per profile. The profiles of a node are grouped under its physical
+		GPUs, read from the nvidia.com/gpu.count and nvidia.com/gpu.product labels of the GPU feature
+		discovery when present.`))
+
+	resourceGPUExample = templates.Examples(i18n.T(`
+		# Show the allocation of the GPUs of all nodes
+		kubectl resource-view gpu
+
+		# Show the allocation of the GPUs of the nodes defined by label nvidia.com/mig.strategy=mixed
+		kubectl resource-view gpu -l nvidia.com/mig.strategy=mixed
+
+		# Export the allocation of every MIG profile to a spreadsheet
+		kubectl resource-view gpu -o csv > gpus.csv
+		`))
+)
+
+func NewCmdResourceGPU(f cmdutil.Factory, o *ResourceGPUOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceGPUOptions{
+			IOStreams: streams,
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "gpu [NAME | -l label]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Display the allocation of the NVIDIA GPUs, MIG profiles and shared GPUs of nodes"),
+		Long:                  resourceGPULong,
+		Example:               resourceGPUExample,
+		ValidArgsFunction:     util.ResourceNameCompletionFunc(f, "node"),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunResourceGPU())
+		},
+		Aliases: []string{"gpus"},
+	}
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter nodes on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVar(&o.NoFormat, "no-format", o.NoFormat, "If present, print output without format table")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml|csv|tsv|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json|custom-columns|custom-columns-file")
+	return cmd
+}
+
+func (o *ResourceGPUOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		o.ResourceName = args[0]
+	} else if len(args) > 1 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = newKubeClient(config)
+	if err != nil {
+		return err
+	}
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
+}
+
+func (o *ResourceGPUOptions) Validate() error {
+	if len(o.ResourceName) > 0 && len(o.Selector) > 0 {
+		return errors.New("only one of NAME or --selector can be provided")
+	}
+	if len(o.Output) > 0 {
+		if err := writer.ValidateOutput(o.Output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o ResourceGPUOptions) RunResourceGPU() error {
+	var err error
+	selector := labels.Everything()
+	if len(o.Selector) > 0 {
+		selector, err = labels.Parse(o.Selector)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// the gpus have no usage, the metrics API is not needed
+	noderesources, err := o.Client.GetNodeResourcesWithoutUsage(ctx, o.ResourceName, selector)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errors.New("operation timed out - too many nodes or slow API response")
+		}
+		return err
+	}
+	kube.SortNodes(noderesources, kube.SortByName, false)
+	data := kube.NodeGPUResources(noderesources)
+	if len(data) == 0 {
+		fmt.Fprintln(o.ErrOut, "No NVIDIA GPU found")
+	}
+
+	if writer.IsCSVOutput(o.Output) {
+		return writer.GPUCSVWrite(o.Out, data, o.Output)
+	}
+	if len(o.Output) > 0 {
+		return writer.ObjectWrite(o.Out, kube.NodeGPUResourceList{Items: data}, o.Output)
+	}
+	writer.GPUWrite(o.Out, data, o.Thresholds, o.NoFormat)
+	return nil
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/writer"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type ResourceNamespaceOptions struct {
+	ResourceName      string
+	ResourceType      string
+	ResourceTypeslice []string
+	LabelSelector     string
+	SortBy            string
+	Output            string
+	NoFormat          bool
+	NoUsage           bool
+
+	DiscoveryClient discovery.DiscoveryInterface
+	Client          *kube.KubeClient
+	Thresholds      kube.Thresholds
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceNamespaceLong = templates.LongDesc(i18n.T(`
+		Display resource (cpu/memory/gpu) usage of namespaces.
+
+		The 'resource-view namespace' command sums up the usage, requests and limits
+		of all pods in each namespace which are neither succeeded nor failed. Without the
+		metrics API, or with --no-usage, the usage is shown as n/a, as is the usage of a
+		namespace if it is unknown for one of its pods, e.g. a pending one.`))
+
+	resourceNamespaceExample = templates.Examples(i18n.T(`
+		# Show metrics for all namespaces
+		kubectl resource-view namespace
+
+		# Show metrics for a given namespace
+		kubectl resource-view namespace NAMESPACE
+
+		# Show metrics for the namespaces sorted by memory usage
+		kubectl resource-view namespace --sort-by=memory
+
+		# Show metrics of the pods defined by label name=myLabel, summed up per namespace
+		kubectl resource-view namespace -l name=myLabel
+		`))
+)
+
+func NewCmdResourceNamespace(f cmdutil.Factory, o *ResourceNamespaceOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceNamespaceOptions{
+			IOStreams: streams,
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "namespace [NAME | -l label]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Display resource (cpu/memory/gpu) usage of namespaces"),
+		Long:                  resourceNamespaceLong,
+		Example:               resourceNamespaceExample,
+		ValidArgsFunction:     util.ResourceNameCompletionFunc(f, "namespace"),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunResourceNamespace())
+		},
+		Aliases: []string{"namespaces", "ns"},
+	}
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter pods on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVarP(&o.ResourceType, "type", "t", o.ResourceType, "Type information hierarchically (default: All Type)[possible values: cpu,memory,gpu],Multiple can be specified, separated by commas")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort namespaces list using specified field. The field can be either 'cpu' or 'memory'.")
+	cmd.Flags().BoolVar(&o.NoFormat, "no-format", o.NoFormat, "If present, print output without format table")
+	cmd.Flags().BoolVar(&o.NoUsage, "no-usage", o.NoUsage, "If present, skip the metrics API and show only the requests, limits and capacity, with the usage as n/a. Used automatically when the metrics API is not available")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml|csv|tsv|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json|custom-columns|custom-columns-file")
+	return cmd
+}
+
+func (o *ResourceNamespaceOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		o.ResourceName = args[0]
+	} else if len(args) > 1 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.DiscoveryClient = clientset.DiscoveryClient
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = newKubeClient(config)
+	if err != nil {
+		return err
+	}
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
+}
+
+func (o *ResourceNamespaceOptions) Validate() error {
+	if len(o.SortBy) > 0 {
+		if o.SortBy != sortByCPU && o.SortBy != sortByMemory {
+			return errors.New("--sort-by accepts only cpu or memory")
+		}
+	}
+	if len(o.Output) > 0 {
+		if err := writer.ValidateOutput(o.Output); err != nil {
+			return err
+		}
+	}
+
+	o.ResourceTypeslice = strings.Split(o.ResourceType, ",")
+	if len(o.ResourceType) > 0 {
+		for _, str := range o.ResourceTypeslice {
+			if !MapKeyInIntSlice(podResourceType, str) {
+				return errors.New("--type accepts only cpu,memory,gpu")
+			}
+		}
+	}
+	return nil
+}
+
+func (o ResourceNamespaceOptions) RunResourceNamespace() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var err error
+	labelSelector := labels.Everything()
+	if len(o.LabelSelector) > 0 {
+		labelSelector, err = labels.Parse(o.LabelSelector)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !o.NoUsage {
+		apiGroups, err := o.DiscoveryClient.ServerGroups()
+		if err != nil {
+			return err
+		}
+		if !usageAvailable(apiGroups) {
+			fmt.Fprintln(o.ErrOut, "metrics API not available, showing requests and limits only")
+			o.NoUsage = true
+		}
+	}
+	allNamespaces := len(o.ResourceName) == 0
+	podresources, err := o.Client.ListPodResources(ctx, o.ResourceName, "", allNamespaces, labelSelector, fields.Everything(), nil, !o.NoUsage)
+	if err != nil {
+		return err
+	}
+
+	if len(podresources) == 0 {
+		if allNamespaces {
+			fmt.Fprintln(o.ErrOut, "No resources found")
+		} else {
+			fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.ResourceName)
+		}
+	}
+	data := kube.NamespaceResources(podresources, o.SortBy)
+
+	if writer.IsCSVOutput(o.Output) {
+		return writer.NamespaceCSVWrite(o.Out, data, o.ResourceTypeslice, o.Output)
+	}
+	if len(o.Output) > 0 {
+		return writer.ObjectWrite(o.Out, kube.NamespaceResourceList{Items: data}, o.Output)
+	}
+	writer.NamespaceWrite(o.Out, data, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+	return nil
+}
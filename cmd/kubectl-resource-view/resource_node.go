@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/discovery"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -28,16 +31,29 @@ type ResourceNodeOptions struct {
 	ResourceName       string
 	ResourceType       string
 	ResourceTypeslice  []string
+	Resources          string
 	Selector           string
 	SortBy             string
+	Reverse            bool
+	Where              string
+	GroupBy            string
+	Output             string
 	NoFormat           bool
+	NoUsage            bool
+	Watch              bool
+	Interval           time.Duration
+	SampleFor          time.Duration
+	SampleEvery        time.Duration
 	UseProtocolBuffers bool
 
 	NodeClient      corev1client.CoreV1Interface
 	Printer         *metricsutil.TopCmdPrinter
 	DiscoveryClient discovery.DiscoveryInterface
+	Filter          *kube.Filter
+	Sampler         *kube.UsageSampler
 	MetricsClient   metricsclientset.Interface
 	Client          *kube.KubeClient
+	Thresholds      kube.Thresholds
 
 	genericclioptions.IOStreams
 }
@@ -46,7 +62,17 @@ var (
 	ResourceNodeLong = templates.LongDesc(i18n.T(`
 		Display resource (cpu/memory/gpu/podcount) usage of nodes.
 
-		The resource node command allows you to see the resource consumption of nodes.`))
+		The resource node command allows you to see the resource consumption of nodes.
+
+		The ephemeral-storage and hugepages types are only shown when requested with --type.
+		The ephemeral-storage usage is the used space of the root filesystem of the node, read from
+		the kubelet summary API, and is shown as n/a when the summary is not available.
+
+		Without the metrics API, or with --no-usage, only the core API is used: the requests, limits
+		and capacity are shown and the usage is n/a.
+
+		With --sample-for, the usage is polled every --sample-every and its min, avg, p95 and max are
+		shown next to the cpu and memory requests and limits, instead of a single sample.`))
 
 	ResourceNodeExample = templates.Examples(i18n.T(`
 		  # Show metrics for all nodes
@@ -58,6 +84,39 @@ var (
 		  # Show metrics for the node defined by type name=cpu,memory,gpu,pod
 		  kubectl resource-view node -t cpu,memory,gpu,pod
 
+		  # Show metrics for all nodes in json format
+		  kubectl resource-view node -o json
+
+		  # Show only the cpu request percentage of all nodes
+		  kubectl resource-view node -o custom-columns=NAME:.name,CPUREQ:.cpu.requestsFraction
+
+		  # Export cpu and memory of all nodes to a spreadsheet
+		  kubectl resource-view node -t cpu,memory -o csv > nodes.csv
+
+		  # Show the ephemeral-storage usage, requests and limits and the hugepages of all nodes
+		  kubectl resource-view node -t ephemeral-storage,hugepages
+
+		  # Show the allocation of AMD GPUs and 2Mi hugepages of all nodes
+		  kubectl resource-view node -t pod --resource amd.com/gpu,hugepages-2Mi
+
+		  # Show metrics for all nodes with a subtotal per zone
+		  kubectl resource-view node --group-by topology.kubernetes.io/zone
+
+		  # Show metrics for all nodes, the least requested cpu first
+		  kubectl resource-view node --sort-by cpu-req --reverse
+
+		  # Show the nodes with more than 90% of their memory requested
+		  kubectl resource-view node --where 'mem.requests.pct>90'
+
+		  # Show the requests, limits and capacity of all nodes without the metrics API
+		  kubectl resource-view node --no-usage
+
+		  # Show the min, avg, p95 and max usage of all nodes over 5 minutes
+		  kubectl resource-view node --sample-for 5m --sample-every 15s
+
+		  # Refresh the metrics of all nodes every 10 seconds
+		  kubectl resource-view node -w --interval 10s
+
 		  `))
 )
 
@@ -65,6 +124,8 @@ func NewCmdResouceNode(f cmdutil.Factory, o *ResourceNodeOptions, streams generi
 	if o == nil {
 		o = &ResourceNodeOptions{
 			IOStreams:          streams,
+			Interval:           5 * time.Second,
+			SampleEvery:        15 * time.Second,
 			UseProtocolBuffers: true,
 		}
 	}
@@ -85,9 +146,19 @@ func NewCmdResouceNode(f cmdutil.Factory, o *ResourceNodeOptions, streams generi
 	}
 
 	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
-	cmd.Flags().StringVarP(&o.ResourceType, "type", "t", o.ResourceType, "Type information hierarchically (default: All Type)[possible values: cpu,memory,pod,gpu], Multiple can be specified, separated by commas")
+	cmd.Flags().StringVarP(&o.ResourceType, "type", "t", o.ResourceType, "Type information hierarchically (default: All Type)[possible values: cpu,memory,pod,gpu,ephemeral-storage,hugepages], Multiple can be specified, separated by commas. The ephemeral-storage usage is read from the kubelet summary API through the nodes/proxy subresource when permitted")
+	cmd.Flags().StringVar(&o.Resources, "resource", o.Resources, "Extended resources to show the requests, limits and capacity of, e.g. amd.com/gpu,hugepages-2Mi, Multiple can be specified, separated by commas")
 	cmd.Flags().BoolVar(&o.NoFormat, "no-format", o.NoFormat, "If present, print output without format table")
-	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort nodes list using specified field. The field can be either 'cpu' or 'memory' ")
+	cmd.Flags().BoolVar(&o.NoUsage, "no-usage", o.NoUsage, "If present, skip the metrics API and show only the requests, limits and capacity, with the usage as n/a. Used automatically when the metrics API is not available")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort nodes list using specified field, from the largest value except for name, a usage shown as n/a last even with --reverse [possible values: "+strings.Join(kube.NodeSortFields, ",")+"]")
+	cmd.Flags().BoolVar(&o.Reverse, "reverse", o.Reverse, "If present, reverse the order of --sort-by, or of the names without --sort-by")
+	cmd.Flags().StringVar(&o.Where, "where", o.Where, "If non-empty, only show the nodes matching the expression, e.g. 'cpu.requests.pct>80 && mem.usage.pct<20', combining comparisons with &&, || and !. A comparison of a usage shown as n/a is neither true nor false, as NULL in SQL [fields: "+strings.Join(kube.NodeFilterFields(), ",")+"]")
+	cmd.Flags().StringVar(&o.GroupBy, "group-by", o.GroupBy, "If non-empty, group nodes by the value of the given label key and print a subtotal per group (e.g. --group-by node.kubernetes.io/instance-type)")
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "If present, refresh the table in place every --interval, highlighting the values changed since the previous refresh")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "The time between two refreshes of --watch")
+	cmd.Flags().DurationVar(&o.SampleFor, "sample-for", o.SampleFor, "If non-zero, poll the usage for this duration and show its min, avg, p95 and max instead of a single sample, e.g. 5m. The usage of --output, --sort-by and --where is the average")
+	cmd.Flags().DurationVar(&o.SampleEvery, "sample-every", o.SampleEvery, "The time between two samples of --sample-for")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml|csv|tsv|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json|custom-columns|custom-columns-file")
 
 	return cmd
 }
@@ -110,32 +181,69 @@ func (o *ResourceNodeOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, ar
 	if err != nil {
 		return err
 	}
-	o.Client, err = kube.NewClient(config)
+	o.Client, err = newKubeClient(config)
 	if err != nil {
 		return err
 	}
-	return nil
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
 }
 
 func (o *ResourceNodeOptions) Validate(cmd *cobra.Command, args []string) error {
 	if len(o.SortBy) > 0 {
-		if o.SortBy != sortByCPU && o.SortBy != sortByMemory {
-			return errors.New("--sort-by accepts only cpu or memory")
+		if err := kube.ValidateNodeSortField(o.SortBy); err != nil {
+			return err
 		}
 	}
+	if len(o.Where) > 0 {
+		filter, err := kube.ParseNodeFilter(o.Where)
+		if err != nil {
+			return fmt.Errorf("invalid --where: %v", err)
+		}
+		o.Filter = filter
+	}
 	if len(o.ResourceName) > 0 && len(o.Selector) > 0 {
 		return errors.New("only one of NAME or --selector can be provided")
 	}
+	if len(o.GroupBy) > 0 {
+		if errs := validation.IsQualifiedName(o.GroupBy); len(errs) > 0 {
+			return fmt.Errorf("invalid --group-by label key %q: %s", o.GroupBy, strings.Join(errs, "; "))
+		}
+	}
+	if o.Watch {
+		if len(o.Output) > 0 {
+			return errors.New("--watch can not be used with --output")
+		}
+		if o.Interval <= 0 {
+			return errors.New("--interval must be greater than zero")
+		}
+	}
+	if len(o.Output) > 0 {
+		if err := writer.ValidateOutput(o.Output); err != nil {
+			return err
+		}
+	}
 
 	o.ResourceTypeslice = strings.Split(o.ResourceType, ",")
 	if len(o.ResourceType) > 0 {
 		for _, str := range o.ResourceTypeslice {
-			if !MapKeyInIntSlice(nodeResourceType, str) {
-				return errors.New("--type accepts only cpu,memory,pod,gpu")
+			if !MapKeyInIntSlice(nodeResourceType, str) && !MapKeyInIntSlice(storageResourceType, str) {
+				return errors.New("--type accepts only cpu,memory,pod,gpu,ephemeral-storage,hugepages")
 			}
 		}
 	}
-	return nil
+	resources, err := parseExtendedResources(o.Resources)
+	if err != nil {
+		return err
+	}
+	o.ResourceTypeslice = append(o.ResourceTypeslice, resources...)
+
+	if o.SampleFor != 0 {
+		if o.Watch || o.NoUsage || len(o.GroupBy) > 0 {
+			return errors.New("--sample-for can not be used with --watch, --no-usage or --group-by")
+		}
+	}
+	return validateSampling(o.SampleFor, o.SampleEvery, o.ResourceTypeslice)
 }
 
 func (o ResourceNodeOptions) RunResourceNode() error {
@@ -148,32 +256,105 @@ func (o ResourceNodeOptions) RunResourceNode() error {
 		}
 	}
 
-	apiGroups, err := o.DiscoveryClient.ServerGroups()
+	if !o.NoUsage {
+		apiGroups, err := o.DiscoveryClient.ServerGroups()
+		if err != nil {
+			return err
+		}
+
+		metricsAPIAvailable := usageAvailable(apiGroups)
+
+		if !metricsAPIAvailable && o.SampleFor > 0 {
+			return errors.New("metrics API not available")
+		}
+		if !metricsAPIAvailable {
+			fmt.Fprintln(o.ErrOut, "metrics API not available, showing requests and limits only")
+			o.NoUsage = true
+		}
+	}
+
+	if o.SampleFor > 0 {
+		fmt.Fprintf(o.ErrOut, "Sampling the usage every %s for %s\n", o.SampleEvery, o.SampleFor)
+		o.Sampler = o.Client.SampleUsage(o.SampleFor, o.SampleEvery)
+	}
+
+	if o.Watch {
+		var frame writer.Frame
+		return runWatch(o.Out, o.Interval, func(out io.Writer) error {
+			data, err := o.getNodeResources(selector)
+			if err != nil {
+				return err
+			}
+			if len(o.GroupBy) > 0 {
+				writer.NodeGroupWrite(out, kube.NodeGroupResources(data, o.GroupBy), o.GroupBy, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+				return nil
+			}
+			frame = writer.NodeWatchWrite(out, data, frame, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+			return nil
+		})
+	}
+
+	data, err := o.getNodeResources(selector)
 	if err != nil {
 		return err
 	}
 
-	metricsAPIAvailable := SupportedMetricsAPIVersionAvailable(apiGroups)
-
-	if !metricsAPIAvailable {
-		return errors.New("metrics API not available")
+	if len(o.GroupBy) > 0 {
+		groups := kube.NodeGroupResources(data, o.GroupBy)
+		if writer.IsCSVOutput(o.Output) {
+			return writer.NodeGroupCSVWrite(o.Out, groups, o.ResourceTypeslice, o.Output)
+		}
+		if len(o.Output) > 0 {
+			return writer.ObjectWrite(o.Out, kube.NodeGroupResourceList{Label: o.GroupBy, Items: groups}, o.Output)
+		}
+		writer.NodeGroupWrite(o.Out, groups, o.GroupBy, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+		return nil
+	}
 
+	if writer.IsCSVOutput(o.Output) {
+		if o.Sampler != nil {
+			return writer.NodeUsageStatsCSVWrite(o.Out, data, o.ResourceTypeslice, o.Output)
+		}
+		return writer.NodeCSVWrite(o.Out, data, o.ResourceTypeslice, o.Output)
 	}
-	//ResourceType := strings.Split(o.ResourceType, ",")
+	if len(o.Output) > 0 {
+		return writer.ObjectWrite(o.Out, kube.NodeResourceList{Items: data}, o.Output)
+	}
+	if o.Sampler != nil {
+		writer.NodeUsageStatsWrite(o.Out, data, o.ResourceTypeslice, o.NoFormat)
+		return nil
+	}
+	writer.NodeWrite(o.Out, data, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+	return nil
+}
 
-	// 添加context用于超时控制
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//getNodeResources fetches the node resources, giving up after 30 seconds plus the sampling time
+func (o ResourceNodeOptions) getNodeResources(selector labels.Selector) ([]kube.NodeResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second+o.SampleFor)
 	defer cancel()
 
-	// 修改GetNodeResources调用，传入context
-	data, err := o.Client.GetNodeResources(ctx, o.ResourceName, o.ResourceTypeslice, o.SortBy, selector)
+	var data []kube.NodeResource
+	var err error
+	if o.NoUsage {
+		data, err = o.Client.GetNodeResourcesWithoutUsage(ctx, o.ResourceName, selector)
+	} else {
+		data, err = o.Client.GetNodeResources(ctx, o.ResourceName, selector)
+	}
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
-			return errors.New("operation timed out - too many nodes or slow API response")
+			return nil, errors.New("operation timed out - too many nodes or slow API response")
 		}
-		return err
+		return nil, err
 	}
-
-	writer.NodeWrite(data, o.ResourceTypeslice, o.NoFormat)
-	return nil
+	if o.Sampler != nil {
+		o.Sampler.SetNodeUsageStats(data)
+	}
+	data = o.Filter.Nodes(data)
+	if MapKeyInIntSlice(o.ResourceTypeslice, "ephemeral-storage") {
+		if err := o.Client.SetNodeStorageUsage(ctx, data); err != nil {
+			return nil, err
+		}
+	}
+	kube.SortNodes(data, o.SortBy, o.Reverse)
+	return data, nil
 }
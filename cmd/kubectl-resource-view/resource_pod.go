@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/discovery"
@@ -30,20 +32,34 @@ type ResourcePodOptions struct {
 	Namespace          string
 	ResourceType       string
 	ResourceTypeslice  []string
+	Resources          string
 	LabelSelector      string
 	FieldSelector      string
+	Phase              string
+	Phases             []corev1.PodPhase
 	SortBy             string
+	Reverse            bool
+	Where              string
+	Output             string
 	NoFormat           bool
+	NoUsage            bool
 	AllNamespaces      bool
 	PrintContainers    bool
+	Watch              bool
+	Interval           time.Duration
+	SampleFor          time.Duration
+	SampleEvery        time.Duration
 	NoHeaders          bool
 	UseProtocolBuffers bool
 
 	PodClient       corev1client.PodsGetter
 	Printer         *metricsutil.TopCmdPrinter
 	DiscoveryClient discovery.DiscoveryInterface
+	Filter          *kube.Filter
+	Sampler         *kube.UsageSampler
 	MetricsClient   metricsclientset.Interface
 	Client          *kube.KubeClient
+	Thresholds      kube.Thresholds
 
 	genericclioptions.IOStreams
 }
@@ -56,8 +72,21 @@ var (
 
 		The 'resource-view pod' command allows you to see the resource consumption of pods.
 
-		Due to the metrics pipeline delay, they may be unavailable for a few minutes
-		since pod creation.`))
+		The pods are listed from the core API and joined with their metrics. Due to the metrics
+		pipeline delay, the usage of a pod may be unavailable for a few minutes since its creation
+		and is shown as n/a, as for the pending pods. The succeeded and failed pods are left out
+		unless requested with --phase.
+
+		The ephemeral-storage and hugepages types are only shown when requested with --type.
+		The ephemeral-storage usage is read from the kubelet summary API and is shown as n/a when
+		the summary is not available. A pod is evicted when its usage exceeds its limit.
+
+		Without the metrics API, or with --no-usage, the active pods are listed from the core API:
+		the requests and limits are shown and the usage is n/a.
+
+		With --sample-for, the usage is polled every --sample-every and its min, avg, p95 and max are
+		shown next to the cpu and memory requests and limits, instead of a single sample that misses
+		the bursts of the pods.`))
 
 	resourcePodExample = templates.Examples(i18n.T(`
 		# Show metrics for all pods in the default namespace
@@ -74,6 +103,36 @@ var (
 
 		# Show metrics for the pods defined by type name=cpu,memory,gpu
 		kubectl resource-view pod -t cpu,memory,gpu
+
+		# Show the ephemeral-storage usage, requests and limits of all pods in all namespaces
+		kubectl resource-view pod -A -t ephemeral-storage
+
+		# Show metrics for every container of the pods in the default namespace
+		kubectl resource-view pod --containers
+
+		# Show metrics for all pods in the default namespace in yaml format
+		kubectl resource-view pod -o yaml
+
+		# Show the memory usage of all pods using a jsonpath template
+		kubectl resource-view pod -o jsonpath='{range .items[*]}{.name}{"\t"}{.memory.usage}{"\n"}{end}'
+
+		# Show metrics for all pods in all namespaces, the closest to their memory limit first
+		kubectl resource-view pod -A --sort-by mem-usage
+
+		# Show the pods of all namespaces using more than 80% of their cpu limit but less than 1Gi of memory
+		kubectl resource-view pod -A --where 'cpu.usage.pct>80 && mem.usage<1Gi'
+
+		# Show the requests of the pending pods of all namespaces, which may be blocking scheduling
+		kubectl resource-view pod -A --phase Pending
+
+		# Show the requests and limits of all pods in the default namespace without the metrics API
+		kubectl resource-view pod --no-usage
+
+		# Show the min, avg, p95 and max usage of the pods of a namespace over 10 minutes
+		kubectl resource-view pod -n NAMESPACE --sample-for 10m
+
+		# Refresh the metrics of all pods in the default namespace every 10 seconds
+		kubectl resource-view pod -w --interval 10s
 		`))
 )
 
@@ -81,6 +140,8 @@ func NewCmdResoucePod(f cmdutil.Factory, o *ResourcePodOptions, streams genericc
 	if o == nil {
 		o = &ResourcePodOptions{
 			IOStreams:          streams,
+			Interval:           5 * time.Second,
+			SampleEvery:        15 * time.Second,
 			UseProtocolBuffers: true,
 		}
 	}
@@ -100,11 +161,22 @@ func NewCmdResoucePod(f cmdutil.Factory, o *ResourcePodOptions, streams genericc
 		Aliases: []string{"pods", "po"},
 	}
 	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
-	cmd.Flags().StringVarP(&o.ResourceType, "type", "t", o.ResourceType, "Type information hierarchically (default: All Type)[possible values: cpu,memory,gpu],Multiple can be specified, separated by commas")
+	cmd.Flags().StringVarP(&o.ResourceType, "type", "t", o.ResourceType, "Type information hierarchically (default: All Type)[possible values: cpu,memory,gpu,ephemeral-storage,hugepages],Multiple can be specified, separated by commas. The ephemeral-storage usage is read from the kubelet summary API through the nodes/proxy subresource when permitted")
+	cmd.Flags().StringVar(&o.Resources, "resource", o.Resources, "Extended resources to show the requests, limits and capacity of, e.g. amd.com/gpu,hugepages-2Mi, Multiple can be specified, separated by commas")
 	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
-	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort pods list using specified field. The field can be either 'cpu' or 'memory'.")
+	cmd.Flags().StringVar(&o.Phase, "phase", o.Phase, "If non-empty, only show the pods in the given phases, e.g. Pending,Running, Multiple can be specified, separated by commas (default: Pending,Running,Unknown)[possible values: Pending,Running,Succeeded,Failed,Unknown]")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort pods list using specified field, from the largest value except for namespace and name, a usage shown as n/a last even with --reverse [possible values: "+strings.Join(kube.PodSortFields, ",")+"]")
+	cmd.Flags().BoolVar(&o.Reverse, "reverse", o.Reverse, "If present, reverse the order of --sort-by, or of the namespaces and names without --sort-by")
+	cmd.Flags().StringVar(&o.Where, "where", o.Where, "If non-empty, only show the pods matching the expression, e.g. 'cpu.usage.pct>80 && mem.usage<1Gi', combining comparisons with &&, || and !. A comparison of a usage shown as n/a is neither true nor false, as NULL in SQL [fields: "+strings.Join(kube.PodFilterFields(), ",")+"]")
 	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().BoolVar(&o.PrintContainers, "containers", o.PrintContainers, "If present, print usage, requests and limits of every container, including init containers, within a pod.")
 	cmd.Flags().BoolVar(&o.NoFormat, "no-format", o.NoFormat, "If present, print output without format table")
+	cmd.Flags().BoolVar(&o.NoUsage, "no-usage", o.NoUsage, "If present, skip the metrics API and show only the requests, limits and capacity, with the usage as n/a. Used automatically when the metrics API is not available")
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "If present, refresh the table in place every --interval, highlighting the values changed since the previous refresh")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "The time between two refreshes of --watch")
+	cmd.Flags().DurationVar(&o.SampleFor, "sample-for", o.SampleFor, "If non-zero, poll the usage for this duration and show its min, avg, p95 and max instead of a single sample, e.g. 5m. The usage of --output, --sort-by and --where is the average")
+	cmd.Flags().DurationVar(&o.SampleEvery, "sample-every", o.SampleEvery, "The time between two samples of --sample-for")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml|csv|tsv|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json|custom-columns|custom-columns-file")
 	return cmd
 }
 
@@ -131,39 +203,88 @@ func (o *ResourcePodOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, arg
 		return err
 	}
 
-	o.Client, err = kube.NewClient(config)
+	o.Client, err = newKubeClient(config)
 	if err != nil {
 		return err
 	}
-	return nil
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
 }
 
 func (o *ResourcePodOptions) Validate() error {
 	if len(o.SortBy) > 0 {
-		if o.SortBy != sortByCPU && o.SortBy != sortByMemory {
-			return errors.New("--sort-by accepts only cpu or memory")
+		if err := kube.ValidatePodSortField(o.SortBy); err != nil {
+			return err
+		}
+	}
+	if len(o.Where) > 0 {
+		filter, err := kube.ParsePodFilter(o.Where)
+		if err != nil {
+			return fmt.Errorf("invalid --where: %v", err)
 		}
+		o.Filter = filter
 	}
 	if len(o.ResourceName) > 0 && len(o.LabelSelector) > 0 {
 		return errors.New("only one of NAME or --selector can be provided")
 	}
+	if o.Watch {
+		if len(o.Output) > 0 {
+			return errors.New("--watch can not be used with --output")
+		}
+		if o.Interval <= 0 {
+			return errors.New("--interval must be greater than zero")
+		}
+	}
+	if len(o.Output) > 0 {
+		if err := writer.ValidateOutput(o.Output); err != nil {
+			return err
+		}
+	}
 
 	o.ResourceTypeslice = strings.Split(o.ResourceType, ",")
 	if len(o.ResourceType) > 0 {
 		for _, str := range o.ResourceTypeslice {
-			if !MapKeyInIntSlice(podResourceType, str) {
-				return errors.New("--type accepts only cpu,memory,gpu")
+			if !MapKeyInIntSlice(podResourceType, str) && !MapKeyInIntSlice(storageResourceType, str) {
+				return errors.New("--type accepts only cpu,memory,gpu,ephemeral-storage,hugepages")
 			}
 		}
 	}
-	return nil
+	resources, err := parseExtendedResources(o.Resources)
+	if err != nil {
+		return err
+	}
+	o.ResourceTypeslice = append(o.ResourceTypeslice, resources...)
+
+	o.Phases = nil
+	if len(o.Phase) > 0 {
+		for _, str := range strings.Split(o.Phase, ",") {
+			phase, ok := parsePodPhase(str)
+			if !ok {
+				return errors.New("--phase accepts only Pending,Running,Succeeded,Failed,Unknown")
+			}
+			o.Phases = append(o.Phases, phase)
+		}
+	}
+
+	if o.SampleFor != 0 {
+		if o.Watch || o.NoUsage || o.PrintContainers {
+			return errors.New("--sample-for can not be used with --watch, --no-usage or --containers")
+		}
+	}
+	return validateSampling(o.SampleFor, o.SampleEvery, o.ResourceTypeslice)
 }
 
-func (o ResourcePodOptions) RunResourcePod() error {
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+//parsePodPhase returns the pod phase named str, ignoring the case
+func parsePodPhase(str string) (corev1.PodPhase, bool) {
+	for _, phase := range kube.PodPhases {
+		if strings.EqualFold(strings.TrimSpace(str), string(phase)) {
+			return phase, true
+		}
+	}
+	return "", false
+}
 
+func (o ResourcePodOptions) RunResourcePod() error {
 	var err error
 	labelSelector := labels.Everything()
 	if len(o.LabelSelector) > 0 {
@@ -180,22 +301,50 @@ func (o ResourcePodOptions) RunResourcePod() error {
 		}
 	}
 
-	apiGroups, err := o.DiscoveryClient.ServerGroups()
-	if err != nil {
-		return err
+	if !o.NoUsage {
+		apiGroups, err := o.DiscoveryClient.ServerGroups()
+		if err != nil {
+			return err
+		}
+
+		metricsAPIAvailable := usageAvailable(apiGroups)
+
+		if !metricsAPIAvailable && o.SampleFor > 0 {
+			return errors.New("metrics API not available")
+		}
+		if !metricsAPIAvailable {
+			fmt.Fprintln(o.ErrOut, "metrics API not available, showing requests and limits only")
+			o.NoUsage = true
+		}
 	}
 
-	metricsAPIAvailable := SupportedMetricsAPIVersionAvailable(apiGroups)
+	if o.SampleFor > 0 {
+		fmt.Fprintf(o.ErrOut, "Sampling the usage every %s for %s\n", o.SampleEvery, o.SampleFor)
+		o.Sampler = o.Client.SampleUsage(o.SampleFor, o.SampleEvery)
+	}
 
-	if !metricsAPIAvailable {
-		return errors.New("metrics API not available")
+	if o.Watch {
+		var frame writer.Frame
+		return runWatch(o.Out, o.Interval, func(out io.Writer) error {
+			data, err := o.getPodResources(labelSelector, fieldSelector)
+			if err != nil {
+				return err
+			}
+			if o.PrintContainers {
+				frame = writer.ContainerWatchWrite(out, data, frame, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+				return nil
+			}
+			frame = writer.PodWatchWrite(out, data, frame, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+			return nil
+		})
 	}
-	metrics, err := o.Client.GetPodMetricsFromMetricsAPI(ctx, o.Namespace, o.ResourceName, o.AllNamespaces, labelSelector, fieldSelector)
+
+	data, err := o.getPodResources(labelSelector, fieldSelector)
 	if err != nil {
 		return err
 	}
 
-	if len(metrics.Items) == 0 {
+	if len(data) == 0 {
 		if o.AllNamespaces {
 			fmt.Fprintln(o.ErrOut, "No resources found")
 		} else {
@@ -203,10 +352,48 @@ func (o ResourcePodOptions) RunResourcePod() error {
 		}
 	}
 
-	data, err := o.Client.GetPodResources(ctx, metrics.Items, o.Namespace, o.ResourceName, o.AllNamespaces, o.ResourceTypeslice, o.SortBy, labelSelector, fieldSelector)
-	if err != nil {
-		return err
+	if writer.IsCSVOutput(o.Output) {
+		if o.Sampler != nil {
+			return writer.PodUsageStatsCSVWrite(o.Out, data, o.ResourceTypeslice, o.Output)
+		}
+		if o.PrintContainers {
+			return writer.ContainerCSVWrite(o.Out, data, o.ResourceTypeslice, o.Output)
+		}
+		return writer.PodCSVWrite(o.Out, data, o.ResourceTypeslice, o.Output)
 	}
-	writer.PodWrite(data, o.ResourceTypeslice, o.NoFormat)
+	if len(o.Output) > 0 {
+		return writer.ObjectWrite(o.Out, kube.PodResourceList{Items: data}, o.Output)
+	}
+	if o.Sampler != nil {
+		writer.PodUsageStatsWrite(o.Out, data, o.ResourceTypeslice, o.NoFormat)
+		return nil
+	}
+	if o.PrintContainers {
+		writer.ContainerWrite(o.Out, data, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+		return nil
+	}
+	writer.PodWrite(o.Out, data, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
 	return nil
 }
+
+//getPodResources fetches the pods and their metrics, giving up after 30 seconds plus the sampling time
+func (o ResourcePodOptions) getPodResources(labelSelector labels.Selector, fieldSelector fields.Selector) ([]kube.PodResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second+o.SampleFor)
+	defer cancel()
+
+	data, err := o.Client.ListPodResources(ctx, o.Namespace, o.ResourceName, o.AllNamespaces, labelSelector, fieldSelector, o.Phases, !o.NoUsage)
+	if err != nil {
+		return nil, err
+	}
+	if o.Sampler != nil {
+		o.Sampler.SetPodUsageStats(data)
+	}
+	data = o.Filter.Pods(data)
+	if MapKeyInIntSlice(o.ResourceTypeslice, "ephemeral-storage") {
+		if err := o.Client.SetPodStorageUsage(ctx, data); err != nil {
+			return nil, err
+		}
+	}
+	kube.SortPods(data, o.SortBy, o.Reverse)
+	return data, nil
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/writer"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type ResourceRecommendOptions struct {
+	ResourceName  string
+	Namespace     string
+	LabelSelector string
+	Headroom      float64
+	LimitFactor   float64
+	Output        string
+	NoFormat      bool
+	AllNamespaces bool
+
+	DiscoveryClient discovery.DiscoveryInterface
+	Client          *kube.KubeClient
+	Thresholds      kube.Thresholds
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceRecommendLong = templates.LongDesc(i18n.T(`
+		Recommend requests and limits of containers from their current usage.
+
+		The 'resource-view recommend' command compares the usage of every container to its requests
+		and limits. The recommended request is the usage plus --headroom percent, the recommended limit
+		is the recommended request times --limit-factor. A container is over-provisioned when its usage
+		is below half of its request, under-provisioned when it has no request, when its usage is above
+		its request or when its usage reaches the cpu-usage or mem-usage warning threshold of its limit.
+		A missing limit is added to the reason and makes a container whose request is ok
+		under-provisioned. The sidecars, i.e. the init containers running with the pod, are recommended
+		as the other containers, the other init containers are skipped.
+
+		With -o patch, a strategic merge patch is printed for every workload with a container which is
+		not ok, setting only the cpu and memory which are not ok to the highest recommendation of the
+		pods of the workload. The usage is a single sample of the metrics API unless --prometheus-url
+		is set, review the recommendations before applying them.`))
+
+	resourceRecommendExample = templates.Examples(i18n.T(`
+		# Recommend requests and limits for the containers of the default namespace
+		kubectl resource-view recommend
+
+		# Recommend with 30% headroom and limits at 1.5 times the requests in all namespaces
+		kubectl resource-view recommend -A --headroom 30 --limit-factor 1.5
+
+		# Recommend from the 95th percentile of the usage of the last 7 days in Prometheus
+		kubectl resource-view recommend --prometheus-url http://localhost:9090 --window 168h --quantile 0.95
+
+		# Patch a deployment with the recommended requests and limits
+		kubectl resource-view recommend WORKLOAD_NAME -o patch > patch.yaml
+		kubectl patch deployment WORKLOAD_NAME --patch-file patch.yaml
+		`))
+)
+
+func NewCmdResourceRecommend(f cmdutil.Factory, o *ResourceRecommendOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceRecommendOptions{
+			IOStreams:   streams,
+			Headroom:    20,
+			LimitFactor: 2,
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "recommend [NAME | -l label]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Recommend requests and limits of containers from their usage"),
+		Long:                  resourceRecommendLong,
+		Example:               resourceRecommendExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunResourceRecommend())
+		},
+		Aliases: []string{"rec"},
+	}
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter pods on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().Float64Var(&o.Headroom, "headroom", o.Headroom, "Percentage added to the usage to get the recommended requests")
+	cmd.Flags().Float64Var(&o.LimitFactor, "limit-factor", o.LimitFactor, "Ratio of the recommended limits to the recommended requests, 0 keeps the current limits")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().BoolVar(&o.NoFormat, "no-format", o.NoFormat, "If present, print output without format table")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: patch|json|yaml|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json|custom-columns|custom-columns-file")
+	return cmd
+}
+
+func (o *ResourceRecommendOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	if len(args) == 1 {
+		o.ResourceName = args[0]
+	} else if len(args) > 1 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.DiscoveryClient = clientset.DiscoveryClient
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = newKubeClient(config)
+	if err != nil {
+		return err
+	}
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
+}
+
+func (o *ResourceRecommendOptions) Validate() error {
+	if len(o.ResourceName) > 0 && len(o.LabelSelector) > 0 {
+		return errors.New("only one of NAME or --selector can be provided")
+	}
+	if o.Headroom < 0 {
+		return errors.New("--headroom must not be negative")
+	}
+	if o.LimitFactor != 0 && o.LimitFactor < 1 {
+		return errors.New("--limit-factor must be 0 or at least 1")
+	}
+	if len(o.Output) > 0 && o.Output != "patch" {
+		if writer.IsCSVOutput(o.Output) {
+			return errors.New("--output accepts only patch, json, yaml or a template")
+		}
+		if err := writer.ValidateOutput(o.Output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o ResourceRecommendOptions) RunResourceRecommend() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var err error
+	labelSelector := labels.Everything()
+	if len(o.LabelSelector) > 0 {
+		labelSelector, err = labels.Parse(o.LabelSelector)
+		if err != nil {
+			return err
+		}
+	}
+
+	apiGroups, err := o.DiscoveryClient.ServerGroups()
+	if err != nil {
+		return err
+	}
+
+	metricsAPIAvailable := usageAvailable(apiGroups)
+
+	if !metricsAPIAvailable {
+		return errors.New("metrics API not available")
+	}
+	podresources, err := o.Client.ListPodResources(ctx, o.Namespace, "", o.AllNamespaces, labelSelector, fields.Everything(), nil, true)
+	if err != nil {
+		return err
+	}
+	recommendations, err := o.Client.GetRecommendations(ctx, podresources, o.Namespace, o.AllNamespaces, kube.RecommendOptions{
+		Headroom:    o.Headroom,
+		LimitFactor: o.LimitFactor,
+		Thresholds:  o.Thresholds,
+	})
+	if err != nil {
+		return err
+	}
+
+	var data []kube.ContainerRecommendation
+	for _, recommendation := range recommendations {
+		if len(o.ResourceName) == 0 || recommendation.WorkloadName == o.ResourceName {
+			data = append(data, recommendation)
+		}
+	}
+
+	if len(data) == 0 {
+		if o.AllNamespaces {
+			fmt.Fprintln(o.ErrOut, "No resources found")
+		} else {
+			fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.Namespace)
+		}
+	}
+
+	switch o.Output {
+	case "":
+		writer.RecommendWrite(o.Out, data, o.NoFormat)
+		return nil
+	case "patch":
+		return writer.PatchWrite(o.Out, kube.WorkloadRecommendations(data))
+	}
+	return writer.ObjectWrite(o.Out, kube.ContainerRecommendationList{Items: data}, o.Output)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type ResourceSnapshotOptions struct {
+	File    string
+	NoUsage bool
+
+	DiscoveryClient discovery.DiscoveryInterface
+	Client          *kube.KubeClient
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceSnapshotLong = templates.LongDesc(i18n.T(`
+		Save the resources of nodes and pods to a file.
+
+		The 'resource-view snapshot save' command writes the requests, limits, capacity and usage of all
+		nodes and of the pods of all namespaces which are neither succeeded nor failed to a versioned json
+		file. Compare two snapshots taken before and after a release or a node pool migration with
+		'resource-view diff'.`))
+
+	resourceSnapshotExample = templates.Examples(i18n.T(`
+		# Save the resources of the cluster before a release
+		kubectl resource-view snapshot save before.json
+
+		# Save the requests and limits only, without reading the usage
+		kubectl resource-view snapshot save before.json --no-usage
+
+		# Save the usage at the 95th percentile of the last day in Prometheus
+		kubectl resource-view snapshot save before.json --prometheus-url http://localhost:9090 --window 24h --quantile 0.95
+		`))
+)
+
+func NewCmdResourceSnapshot(f cmdutil.Factory, o *ResourceSnapshotOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceSnapshotOptions{
+			IOStreams: streams,
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "snapshot",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Save the resources of nodes and pods to a file"),
+		Long:                  resourceSnapshotLong,
+		Example:               resourceSnapshotExample,
+		Run:                   runHelp,
+	}
+
+	save := &cobra.Command{
+		Use:                   "save FILE",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Save the resources of nodes and pods to a file"),
+		Long:                  resourceSnapshotLong,
+		Example:               resourceSnapshotExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.RunResourceSnapshot())
+		},
+	}
+	save.Flags().BoolVar(&o.NoUsage, "no-usage", o.NoUsage, "If present, skip the metrics API and save only the requests, limits and capacity, with an unknown usage. Used automatically when the metrics API is not available")
+	cmd.AddCommand(save)
+	return cmd
+}
+
+func (o *ResourceSnapshotOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+	o.File = args[0]
+
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.DiscoveryClient = clientset.DiscoveryClient
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = newKubeClient(config)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o ResourceSnapshotOptions) RunResourceSnapshot() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !o.NoUsage {
+		apiGroups, err := o.DiscoveryClient.ServerGroups()
+		if err != nil {
+			return err
+		}
+		if !usageAvailable(apiGroups) {
+			fmt.Fprintln(o.ErrOut, "metrics API not available, saving requests and limits only")
+			o.NoUsage = true
+		}
+	}
+
+	snapshot, err := o.Client.GetSnapshot(ctx, !o.NoUsage)
+	if err != nil {
+		return err
+	}
+	if err := kube.SaveSnapshot(o.File, snapshot); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.ErrOut, "Saved %d nodes and %d pods to %s\n", len(snapshot.Nodes), len(snapshot.Pods), o.File)
+	return nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/tui"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type ResourceTopOptions struct {
+	ResourceType      string
+	ResourceTypeslice []string
+	Interval          time.Duration
+	NoUsage           bool
+
+	DiscoveryClient discovery.DiscoveryInterface
+	Client          *kube.KubeClient
+	Thresholds      kube.Thresholds
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceTopLong = templates.LongDesc(i18n.T(`
+		Display resource (cpu/memory/gpu/podcount) usage of nodes and pods in a full screen dashboard.
+
+		The 'resource-view top' command refreshes the nodes and pods every --interval. Use tab to
+		switch between nodes and pods, the arrow keys to select a row and to change the sort column,
+		r to reverse the order, n to filter pods by namespace, l to filter by label selector, enter
+		to show the pods of the selected node, esc to go back and q to quit. Without the metrics
+		API, or with --no-usage, the usage is shown as n/a.`))
+
+	resourceTopExample = templates.Examples(i18n.T(`
+		# Show the dashboard of the cluster
+		kubectl resource-view top
+
+		# Show only cpu and memory, refreshed every 10 seconds
+		kubectl resource-view top -t cpu,memory --interval 10s
+		`))
+)
+
+func NewCmdResourceTop(f cmdutil.Factory, o *ResourceTopOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceTopOptions{
+			IOStreams: streams,
+			Interval:  5 * time.Second,
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "top",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Display resource (cpu/memory/gpu/podcount) usage of nodes and pods in a dashboard"),
+		Long:                  resourceTopLong,
+		Example:               resourceTopExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunResourceTop())
+		},
+	}
+	cmd.Flags().StringVarP(&o.ResourceType, "type", "t", o.ResourceType, "Type information hierarchically (default: All Type)[possible values: cpu,memory,pod,gpu], Multiple can be specified, separated by commas")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "The time between two refreshes of the dashboard")
+	cmd.Flags().BoolVar(&o.NoUsage, "no-usage", o.NoUsage, "If present, skip the metrics API and show only the requests, limits and capacity, with the usage as n/a. Used automatically when the metrics API is not available")
+	return cmd
+}
+
+func (o *ResourceTopOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.DiscoveryClient = clientset.DiscoveryClient
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = newKubeClient(config)
+	if err != nil {
+		return err
+	}
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
+}
+
+func (o *ResourceTopOptions) Validate() error {
+	if o.Interval <= 0 {
+		return errors.New("--interval must be greater than zero")
+	}
+
+	o.ResourceTypeslice = strings.Split(o.ResourceType, ",")
+	if len(o.ResourceType) > 0 {
+		for _, str := range o.ResourceTypeslice {
+			if !MapKeyInIntSlice(nodeResourceType, str) {
+				return errors.New("--type accepts only cpu,memory,pod,gpu")
+			}
+		}
+	}
+	return nil
+}
+
+func (o ResourceTopOptions) RunResourceTop() error {
+	in, ok := o.In.(*os.File)
+	if !ok {
+		return errors.New("top requires an interactive terminal")
+	}
+
+	if !o.NoUsage {
+		apiGroups, err := o.DiscoveryClient.ServerGroups()
+		if err != nil {
+			return err
+		}
+		if !usageAvailable(apiGroups) {
+			fmt.Fprintln(o.ErrOut, "metrics API not available, showing requests and limits only")
+			o.NoUsage = true
+		}
+	}
+
+	dashboard := &tui.Dashboard{
+		Client:       o.Client,
+		Interval:     o.Interval,
+		ResourceType: o.ResourceTypeslice,
+		NoUsage:      o.NoUsage,
+		Thresholds:   o.Thresholds,
+	}
+	return dashboard.Run(in, o.Out)
+}
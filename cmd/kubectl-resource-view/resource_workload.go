@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/writer"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type ResourceWorkloadOptions struct {
+	ResourceName      string
+	Namespace         string
+	ResourceType      string
+	ResourceTypeslice []string
+	LabelSelector     string
+	SortBy            string
+	Output            string
+	NoFormat          bool
+	AllNamespaces     bool
+	NoUsage           bool
+
+	DiscoveryClient discovery.DiscoveryInterface
+	Client          *kube.KubeClient
+	Thresholds      kube.Thresholds
+
+	genericclioptions.IOStreams
+}
+
+var (
+	resourceWorkloadLong = templates.LongDesc(i18n.T(`
+		Display resource (cpu/memory/gpu) usage of workloads.
+
+		The 'resource-view workload' command groups pods by the Deployment, StatefulSet,
+		DaemonSet, CronJob or Job owning them and shows the total and per replica
+		usage, requests and limits of their pods which are neither succeeded nor failed.
+		Pods without a controller are shown as kind Pod. Without the metrics API, or
+		with --no-usage, the usage is shown as n/a, as is the usage of a workload if it
+		is unknown for one of its pods, e.g. a pending one.`))
+
+	resourceWorkloadExample = templates.Examples(i18n.T(`
+		# Show metrics for all workloads in the default namespace
+		kubectl resource-view workload
+
+		# Show metrics for all workloads in all namespaces sorted by memory usage
+		kubectl resource-view workload -A --sort-by=memory
+
+		# Show metrics for a given workload
+		kubectl resource-view workload WORKLOAD_NAME
+		`))
+)
+
+func NewCmdResourceWorkload(f cmdutil.Factory, o *ResourceWorkloadOptions, streams genericclioptions.IOStreams) *cobra.Command {
+	if o == nil {
+		o = &ResourceWorkloadOptions{
+			IOStreams: streams,
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "workload [NAME | -l label]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Display resource (cpu/memory/gpu) usage of workloads"),
+		Long:                  resourceWorkloadLong,
+		Example:               resourceWorkloadExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunResourceWorkload())
+		},
+		Aliases: []string{"workloads", "wl"},
+	}
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter pods on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVarP(&o.ResourceType, "type", "t", o.ResourceType, "Type information hierarchically (default: All Type)[possible values: cpu,memory,gpu],Multiple can be specified, separated by commas")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort workloads list using specified field. The field can be either 'cpu' or 'memory'.")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().BoolVar(&o.NoFormat, "no-format", o.NoFormat, "If present, print output without format table")
+	cmd.Flags().BoolVar(&o.NoUsage, "no-usage", o.NoUsage, "If present, skip the metrics API and show only the requests, limits and capacity, with the usage as n/a. Used automatically when the metrics API is not available")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml|csv|tsv|go-template|go-template-file|jsonpath|jsonpath-file|jsonpath-as-json|custom-columns|custom-columns-file")
+	return cmd
+}
+
+func (o *ResourceWorkloadOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	if len(args) == 1 {
+		o.ResourceName = args[0]
+	} else if len(args) > 1 {
+		return cmdutil.UsageErrorf(cmd, "%s", cmd.Use)
+	}
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.DiscoveryClient = clientset.DiscoveryClient
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = newKubeClient(config)
+	if err != nil {
+		return err
+	}
+	o.Thresholds, err = thresholdsFromFlags(cmd.Flags())
+	return err
+}
+
+func (o *ResourceWorkloadOptions) Validate() error {
+	if len(o.SortBy) > 0 {
+		if o.SortBy != sortByCPU && o.SortBy != sortByMemory {
+			return errors.New("--sort-by accepts only cpu or memory")
+		}
+	}
+	if len(o.ResourceName) > 0 && len(o.LabelSelector) > 0 {
+		return errors.New("only one of NAME or --selector can be provided")
+	}
+	if len(o.Output) > 0 {
+		if err := writer.ValidateOutput(o.Output); err != nil {
+			return err
+		}
+	}
+
+	o.ResourceTypeslice = strings.Split(o.ResourceType, ",")
+	if len(o.ResourceType) > 0 {
+		for _, str := range o.ResourceTypeslice {
+			if !MapKeyInIntSlice(podResourceType, str) {
+				return errors.New("--type accepts only cpu,memory,gpu")
+			}
+		}
+	}
+	return nil
+}
+
+func (o ResourceWorkloadOptions) RunResourceWorkload() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var err error
+	labelSelector := labels.Everything()
+	if len(o.LabelSelector) > 0 {
+		labelSelector, err = labels.Parse(o.LabelSelector)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !o.NoUsage {
+		apiGroups, err := o.DiscoveryClient.ServerGroups()
+		if err != nil {
+			return err
+		}
+		if !usageAvailable(apiGroups) {
+			fmt.Fprintln(o.ErrOut, "metrics API not available, showing requests and limits only")
+			o.NoUsage = true
+		}
+	}
+	podresources, err := o.Client.ListPodResources(ctx, o.Namespace, "", o.AllNamespaces, labelSelector, fields.Everything(), nil, !o.NoUsage)
+	if err != nil {
+		return err
+	}
+	workloads, err := o.Client.GetWorkloadResources(ctx, podresources, o.Namespace, o.AllNamespaces, o.SortBy)
+	if err != nil {
+		return err
+	}
+
+	var data []kube.WorkloadResource
+	for _, workload := range workloads {
+		if len(o.ResourceName) == 0 || workload.Name == o.ResourceName {
+			data = append(data, workload)
+		}
+	}
+
+	if len(data) == 0 {
+		if o.AllNamespaces {
+			fmt.Fprintln(o.ErrOut, "No resources found")
+		} else {
+			fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.Namespace)
+		}
+	}
+
+	if writer.IsCSVOutput(o.Output) {
+		return writer.WorkloadCSVWrite(o.Out, data, o.ResourceTypeslice, o.Output)
+	}
+	if len(o.Output) > 0 {
+		return writer.ObjectWrite(o.Out, kube.WorkloadResourceList{Items: data}, o.Output)
+	}
+	writer.WorkloadWrite(o.Out, data, o.ResourceTypeslice, o.Thresholds, o.NoFormat)
+	return nil
+}
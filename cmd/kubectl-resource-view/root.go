@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
@@ -20,6 +26,8 @@ const (
 var (
 	nodeResourceType = []string{"cpu", "memory", "pod", "gpu"}
 	podResourceType  = []string{"cpu", "memory", "gpu"}
+	// storageResourceType are the types measured in bytes shown by the node and pod commands only
+	storageResourceType = []string{"ephemeral-storage", "hugepages"}
 )
 
 var (
@@ -34,10 +42,24 @@ var (
 
 		The resource command allows you to see the resource consumption for nodes or pods.
 
-		This command requires Metrics Server to be correctly configured and working on the server. `))
+		The usage requires Metrics Server to be correctly configured and working on the server. Without it,
+		the commands show the requests, limits and capacity only, except recommend which needs the usage.
+
+		With --prometheus-url, the usage is read from the cAdvisor metrics of a Prometheus server instead,
+		averaged over --window or at the --quantile of the window, e.g. to size the requests on the
+		95th percentile of the last day rather than on a single sample. `))
 	rolesumExample = templates.Examples(i18n.T(`
 	   node        Display Resource (cpu/memory/gpu/podcount) usage of nodes
-	   pod         Display Resource (cpu/memory/gpu)          usage of pods`))
+	   pod         Display Resource (cpu/memory/gpu)          usage of pods
+	   namespace   Display Resource (cpu/memory/gpu)          usage of namespaces
+	   workload    Display Resource (cpu/memory/gpu)          usage of workloads
+	   cluster     Display Resource (cpu/memory/gpu/podcount) usage of the cluster
+	   top         Display Resource (cpu/memory/gpu/podcount) usage of nodes and pods in a dashboard
+	   check       Check resource percentages against the thresholds
+	   recommend   Recommend requests and limits of containers from their usage
+	   gpu         Display the allocation of the NVIDIA GPUs, MIG profiles and shared GPUs of nodes
+	   snapshot    Save the resources of nodes and pods to a file
+	   diff        Compare two snapshots of the resources of nodes and pods`))
 )
 
 func runHelp(cmd *cobra.Command, args []string) {
@@ -70,6 +92,10 @@ func NewCmdResource() *cobra.Command {
 			//brutil.CheckErr(Validate(cmd, args))
 		},
 	}
+	thresholdOptions := NewThresholdOptions()
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return usage.Validate(cmd.Flags())
+	}
 	//cmd.SetVersionTemplate(brutil.VersionTemplate)
 	//cmd.SetUsageTemplate(brutil.UsageTemplate)
 
@@ -78,6 +104,8 @@ func NewCmdResource() *cobra.Command {
 	cfgFlags.AddFlags(fsets)
 	matchVersionFlags := cmdutil.NewMatchVersionFlags(cfgFlags)
 	matchVersionFlags.AddFlags(fsets)
+	thresholdOptions.AddFlags(fsets)
+	usage.AddFlags(fsets)
 
 	f := cmdutil.NewFactory(matchVersionFlags)
 	streams := genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
@@ -85,6 +113,15 @@ func NewCmdResource() *cobra.Command {
 	//create subcommands
 	cmd.AddCommand(NewCmdResouceNode(f, nil, streams))
 	cmd.AddCommand(NewCmdResoucePod(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceNamespace(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceWorkload(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceCluster(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceTop(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceCheck(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceRecommend(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceGPU(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceSnapshot(f, nil, streams))
+	cmd.AddCommand(NewCmdResourceDiff(f, nil, streams))
 
 	return cmd
 }
@@ -106,6 +143,24 @@ func SupportedMetricsAPIVersionAvailable(discoveredAPIGroups *metav1.APIGroupLis
 	return false
 }
 
+//parseExtendedResources parses the comma separated extended resource names of --resource,
+//their columns are shown after the ones of --type
+func parseExtendedResources(resources string) ([]string, error) {
+	if len(resources) == 0 {
+		return nil, nil
+	}
+	names := strings.Split(resources, ",")
+	for _, name := range names {
+		if MapKeyInIntSlice(nodeResourceType, name) || !kube.IsExtendedResourceName(corev1.ResourceName(name)) {
+			return nil, fmt.Errorf("--resource accepts only extended resources, use --type for %s", name)
+		}
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid --resource name %q: %s", name, strings.Join(errs, "; "))
+		}
+	}
+	return names, nil
+}
+
 //MapKeyInIntSlice
 func MapKeyInIntSlice(haystack []string, needle string) bool {
 	set := make(map[string]struct{})
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+
+	"github.com/spf13/pflag"
+)
+
+// ThresholdOptions are the flags setting the warning and critical thresholds of the percentage columns
+type ThresholdOptions struct {
+	Warning  float64
+	Critical float64
+	File     string
+}
+
+//NewThresholdOptions
+func NewThresholdOptions() *ThresholdOptions {
+	defaults := kube.DefaultThresholds()
+	return &ThresholdOptions{
+		Warning:  defaults.Default.Warning,
+		Critical: defaults.Default.Critical,
+	}
+}
+
+//AddFlags
+func (o *ThresholdOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.Float64Var(&o.Warning, "warn", o.Warning, "Percentage above which a percentage column is marked as warning (yellow). Overrides the default of --thresholds-file, not its columns")
+	flags.Float64Var(&o.Critical, "crit", o.Critical, "Percentage above which a percentage column is marked as critical (red). Overrides the default of --thresholds-file, not its columns. Below the warning percentage, --warn must be set too")
+	flags.StringVar(&o.File, "thresholds-file", o.File, "Path to a yaml file with the warning and critical percentages per column, e.g. 'cpu-req: {warning: 80, critical: 90}'. Columns: default,cpu-req,cpu-limit,cpu-usage,mem-req,mem-limit,mem-usage,gpu-req,gpu-limit,pod")
+}
+
+//ToThresholds returns the default thresholds, overridden by the thresholds file, whose default threshold is
+//overridden by --warn and --crit if set. A lone --crit below the warning threshold is refused rather than
+//lowering the warning threshold silently.
+func (o *ThresholdOptions) ToThresholds(flags *pflag.FlagSet) (kube.Thresholds, error) {
+	thresholds := kube.DefaultThresholds()
+	if len(o.File) > 0 {
+		var err error
+		thresholds, err = kube.LoadThresholds(o.File, thresholds)
+		if err != nil {
+			return thresholds, err
+		}
+	}
+	if flags.Changed("warn") {
+		thresholds.Default.Warning = o.Warning
+	}
+	if flags.Changed("crit") {
+		if !flags.Changed("warn") && thresholds.Default.Warning > o.Critical {
+			return thresholds, fmt.Errorf("--crit %v is below the warning threshold %v, set --warn too", o.Critical, thresholds.Default.Warning)
+		}
+		thresholds.Default.Critical = o.Critical
+	}
+	return thresholds, thresholds.Validate()
+}
+
+//thresholdsFromFlags returns the thresholds of the --warn, --crit and --thresholds-file flags which the
+//commands inherit from the root command
+func thresholdsFromFlags(flags *pflag.FlagSet) (kube.Thresholds, error) {
+	o := NewThresholdOptions()
+	var err error
+	if o.Warning, err = flags.GetFloat64("warn"); err != nil {
+		return kube.Thresholds{}, err
+	}
+	if o.Critical, err = flags.GetFloat64("crit"); err != nil {
+		return kube.Thresholds{}, err
+	}
+	if o.File, err = flags.GetString("thresholds-file"); err != nil {
+		return kube.Thresholds{}, err
+	}
+	return o.ToThresholds(flags)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+
+	"github.com/spf13/pflag"
+)
+
+//parseThresholds parses the threshold flags of args and reads them back as a command inheriting them does
+func parseThresholds(t *testing.T, args ...string) (kube.Thresholds, error) {
+	t.Helper()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	NewThresholdOptions().AddFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return thresholdsFromFlags(flags)
+}
+
+func TestToThresholds(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    kube.Threshold
+		wantErr string
+	}{
+		{args: nil, want: kube.Threshold{Warning: 90, Critical: 95}},
+		{args: []string{"--warn", "70"}, want: kube.Threshold{Warning: 70, Critical: 95}},
+		{args: []string{"--crit", "97"}, want: kube.Threshold{Warning: 90, Critical: 97}},
+		{args: []string{"--crit", "90"}, want: kube.Threshold{Warning: 90, Critical: 90}},
+		// a lone --crit below the warning threshold does not lower it silently
+		{args: []string{"--crit", "85"}, wantErr: "--crit 85 is below the warning threshold 90, set --warn too"},
+		{args: []string{"--warn", "80", "--crit", "85"}, want: kube.Threshold{Warning: 80, Critical: 85}},
+		{args: []string{"--warn", "90", "--crit", "85"}, wantErr: "warning threshold 90 is above critical threshold 85"},
+		{args: []string{"--warn", "97"}, wantErr: "warning threshold 97 is above critical threshold 95"},
+	}
+	for _, test := range tests {
+		thresholds, err := parseThresholds(t, test.args...)
+		if len(test.wantErr) > 0 {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%v: got error %v, want %q", test.args, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", test.args, err)
+			continue
+		}
+		if thresholds.Default != test.want {
+			t.Errorf("%v: got %+v, want %+v", test.args, thresholds.Default, test.want)
+		}
+	}
+}
+
+// TestCheckCritExample runs the example check --target cluster -l pool=default --columns mem-req --warn 80 --crit 85
+func TestCheckCritExample(t *testing.T) {
+	thresholds, err := parseThresholds(t, "--warn", "80", "--crit", "85")
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := []string{kube.ThresholdMemRequests}
+
+	for _, test := range []struct {
+		fraction  float64
+		want      string
+		threshold float64
+	}{
+		{fraction: 80},
+		{fraction: 81, want: "warning", threshold: 80},
+		{fraction: 85, want: "warning", threshold: 80},
+		{fraction: 86, want: "critical", threshold: 85},
+	} {
+		cluster := kube.ClusterResource{}
+		cluster.MemoryRequestsFraction = test.fraction
+		// the usage is not checked, it is above the thresholds to make sure
+		cluster.CPUUsagesFraction, cluster.MemoryUsagesFraction = 99, 99
+
+		violations := kube.ClusterViolations("pool=default", cluster, thresholds, columns, kube.LevelWarning)
+		if len(test.want) == 0 {
+			if len(violations) > 0 {
+				t.Errorf("mem-req %v%%: got %+v, want no violation", test.fraction, violations)
+			}
+			continue
+		}
+		if len(violations) != 1 {
+			t.Fatalf("mem-req %v%%: got %+v, want one violation", test.fraction, violations)
+		}
+		if v := violations[0]; v.Column != kube.ThresholdMemRequests || v.Level != test.want || v.Threshold != test.threshold {
+			t.Errorf("mem-req %v%%: got %+v, want a %s violation of mem-req above %v", test.fraction, v, test.want, test.threshold)
+		}
+	}
+}
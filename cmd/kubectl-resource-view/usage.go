@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// usage is the source of the usage set by the flags of UsageOptions, once the command line is parsed
+var usage = NewUsageOptions()
+
+// UsageOptions are the flags choosing where the usage of nodes and pods is read from
+type UsageOptions struct {
+	PrometheusURL string
+	Window        time.Duration
+	Quantile      float64
+}
+
+//NewUsageOptions
+func NewUsageOptions() *UsageOptions {
+	return &UsageOptions{
+		Window: 5 * time.Minute,
+	}
+}
+
+//AddFlags
+func (o *UsageOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.PrometheusURL, "prometheus-url", o.PrometheusURL, "If non-empty, read the usage from the container_cpu_usage_seconds_total and container_memory_working_set_bytes metrics of this Prometheus server instead of the metrics API, e.g. http://prometheus.monitoring:9090")
+	flags.DurationVar(&o.Window, "window", o.Window, "The time range the usage is averaged over with --prometheus-url")
+	flags.Float64Var(&o.Quantile, "quantile", o.Quantile, "If greater than 0, the usage is this quantile over --window instead of the average, e.g. 0.95, with --prometheus-url")
+}
+
+//Validate
+func (o *UsageOptions) Validate(flags *pflag.FlagSet) error {
+	if len(o.PrometheusURL) == 0 && (flags.Changed("window") || flags.Changed("quantile")) {
+		return errors.New("--window and --quantile can only be used with --prometheus-url")
+	}
+	if o.Window < time.Second {
+		return errors.New("--window must be at least 1s")
+	}
+	if o.Quantile < 0 || o.Quantile > 1 {
+		return errors.New("--quantile must be between 0 and 1")
+	}
+	return nil
+}
+
+//validateSampling validates --sample-for and --sample-every, whose table has only the cpu and memory columns
+func validateSampling(sampleFor, sampleEvery time.Duration, resourceTypes []string) error {
+	if sampleFor == 0 {
+		return nil
+	}
+	if sampleFor < 0 || sampleEvery <= 0 {
+		return errors.New("--sample-for and --sample-every must be greater than zero")
+	}
+	if sampleEvery > sampleFor {
+		return errors.New("--sample-every must not be greater than --sample-for")
+	}
+	for _, t := range resourceTypes {
+		if t != "" && t != "cpu" && t != "memory" {
+			return errors.New("--sample-for shows only the cpu and memory, --type accepts only cpu,memory and --resource can not be used")
+		}
+	}
+	return nil
+}
+
+//newKubeClient creates the client of the commands, reading the usage from Prometheus with --prometheus-url
+func newKubeClient(config *rest.Config) (*kube.KubeClient, error) {
+	client, err := kube.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(usage.PrometheusURL) == 0 {
+		return client, nil
+	}
+	if err := client.UsePrometheus(usage.PrometheusURL, usage.Window, usage.Quantile); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+//usageAvailable reports whether the usage can be read, from Prometheus or from a supported metrics API version
+func usageAvailable(discoveredAPIGroups *metav1.APIGroupList) bool {
+	return len(usage.PrometheusURL) > 0 || SupportedMetricsAPIVersionAvailable(discoveredAPIGroups)
+}
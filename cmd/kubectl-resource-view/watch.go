@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+//runWatch renders every interval until interrupted, redrawing the screen in place.
+//An error of render is printed in place of the table, the next refresh tries again.
+func runWatch(out io.Writer, interval time.Duration, render func(out io.Writer) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var buf bytes.Buffer
+		if err := render(&buf); err != nil {
+			fmt.Fprintf(&buf, "error: %v\n", err)
+		}
+		fmt.Fprintf(out, "%sEvery %s: %s\n\n", clearScreen, interval, time.Now().Format(time.RFC1123))
+		if _, err := buf.WriteTo(out); err != nil {
+			return err
+		}
+		<-ticker.C
+	}
+}
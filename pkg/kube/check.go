@@ -0,0 +1,184 @@
+package kube
+
+import "fmt"
+
+// Kinds of the objects a violation is found on.
+const (
+	KindNode    = "Node"
+	KindPod     = "Pod"
+	KindCluster = "Cluster"
+)
+
+// Violation is a percentage column of a node, pod or cluster above its threshold.
+type Violation struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+
+	// Column is the name of the percentage column, e.g. cpu-req.
+	Column string `json:"column"`
+
+	Percentage float64 `json:"percentage"`
+
+	// Threshold is the percentage exceeded, the warning or the critical one of Column.
+	Threshold float64 `json:"threshold"`
+
+	Level string `json:"level"`
+}
+
+// ViolationList is the structured output of the check command.
+type ViolationList struct {
+	Items []Violation `json:"items"`
+}
+
+// percentage is the value of a percentage column.
+type percentage struct {
+	column string
+	value  float64
+}
+
+// String returns ok, warning or critical.
+func (l Level) String() string {
+	switch l {
+	case LevelWarning:
+		return "warning"
+	case LevelCritical:
+		return "critical"
+	default:
+		return "ok"
+	}
+}
+
+// ParseLevel parses warning or critical.
+func ParseLevel(s string) (Level, error) {
+	switch s {
+	case "warning":
+		return LevelWarning, nil
+	case "critical":
+		return LevelCritical, nil
+	}
+	return LevelOK, fmt.Errorf("unknown level %q, expected warning or critical", s)
+}
+
+// NodeViolations returns the percentage columns of nodes at or above level.
+// Only the given columns are checked, all of them if columns is empty.
+func NodeViolations(noderesources []NodeResource, t Thresholds, columns []string, level Level) []Violation {
+	var violations []Violation
+	for _, r := range noderesources {
+		violations = append(violations, findViolations(KindNode, "", r.Name, nodePercentages(r.NodeAllocatedResources), t, columns, level)...)
+	}
+	return violations
+}
+
+// PodViolations returns the percentage columns of pods at or above level.
+// Only the given columns are checked, all of them if columns is empty.
+func PodViolations(podresources []PodResource, t Thresholds, columns []string, level Level) []Violation {
+	var violations []Violation
+	for _, r := range podresources {
+		violations = append(violations, findViolations(KindPod, r.Namespace, r.Name, podPercentages(r.PodAllocatedResources), t, columns, level)...)
+	}
+	return violations
+}
+
+// ClusterViolations returns the percentage columns of the cluster at or above level.
+// Only the given columns are checked, all of them if columns is empty. An unknown usage is not checked.
+func ClusterViolations(name string, cluster ClusterResource, t Thresholds, columns []string, level Level) []Violation {
+	return findViolations(KindCluster, "", name, nodePercentages(cluster.NodeAllocatedResources), t, columns, level)
+}
+
+// PodColumns lists the percentage columns of pods, the other columns need the capacity of a node.
+var PodColumns = []string{ThresholdCPUUsage, ThresholdMemUsage}
+
+// UnknownNodeUsages returns the names of the nodes whose cpu or memory usage is unknown, e.g. nodes
+// which just joined, whose cpu-usage and mem-usage can not be checked.
+func UnknownNodeUsages(noderesources []NodeResource) []string {
+	var names []string
+	for _, r := range noderesources {
+		if r.CPUUsages == nil || r.MemoryUsages == nil {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// UnknownPodUsages returns the namespace/name of the pods whose cpu or memory usage is unknown.
+func UnknownPodUsages(podresources []PodResource) []string {
+	var names []string
+	for _, r := range podresources {
+		if r.CPUUsages == nil || r.MemoryUsages == nil {
+			names = append(names, r.Namespace+"/"+r.Name)
+		}
+	}
+	return names
+}
+
+//findViolations returns the percentages at or above level
+func findViolations(kind, namespace, name string, percentages []percentage, t Thresholds, columns []string, level Level) []Violation {
+	var violations []Violation
+	for _, p := range percentages {
+		if len(columns) > 0 && !contains(columns, p.column) {
+			continue
+		}
+		l := t.Level(p.column, p.value)
+		if l < level {
+			continue
+		}
+		threshold := t.For(p.column).Warning
+		if l == LevelCritical {
+			threshold = t.For(p.column).Critical
+		}
+		violations = append(violations, Violation{
+			Kind:       kind,
+			Namespace:  namespace,
+			Name:       name,
+			Column:     p.column,
+			Percentage: p.value,
+			Threshold:  threshold,
+			Level:      l.String(),
+		})
+	}
+	return violations
+}
+
+//nodePercentages returns the percentages of a node or of the cluster, the usage ones of the capacity
+//and without the unknown ones
+func nodePercentages(r NodeAllocatedResources) []percentage {
+	percentages := []percentage{
+		{ThresholdCPURequests, r.CPURequestsFraction},
+		{ThresholdCPULimits, r.CPULimitsFraction},
+		{ThresholdMemRequests, r.MemoryRequestsFraction},
+		{ThresholdMemLimits, r.MemoryLimitsFraction},
+		{ThresholdGPURequests, r.NvidiaGpuCountsRequestsFraction},
+		{ThresholdGPULimits, r.NvidiaGpuCountsLimitsFraction},
+		{ThresholdPods, r.PodFraction},
+	}
+	if r.CPUUsages != nil {
+		percentages = append(percentages, percentage{ThresholdCPUUsage, calcPercentage(r.CPUUsages.MilliValue(), r.CPUCapacity.MilliValue())})
+	}
+	if r.MemoryUsages != nil {
+		percentages = append(percentages, percentage{ThresholdMemUsage, calcPercentage(r.MemoryUsages.Value(), r.MemoryCapacity.Value())})
+	}
+	return percentages
+}
+
+//podPercentages returns the usage percentages of a pod, without the unknown ones
+func podPercentages(r PodAllocatedResources) []percentage {
+	var percentages []percentage
+	if r.CPUUsages != nil {
+		percentages = append(percentages, percentage{ThresholdCPUUsage, r.CPUUsagesFraction})
+	}
+	if r.MemoryUsages != nil {
+		percentages = append(percentages, percentage{ThresholdMemUsage, r.MemoryUsagesFraction})
+	}
+	return percentages
+}
+
+//contains
+func contains(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package kube
+
+import "testing"
+
+//violationColumns describes violations by name, column and level, e.g. node-0:cpu-usage:critical
+func violationColumns(violations []Violation) []string {
+	var columns []string
+	for _, v := range violations {
+		columns = append(columns, v.Name+":"+v.Column+":"+v.Level)
+	}
+	return columns
+}
+
+func TestNodeViolationsUsage(t *testing.T) {
+	busy := testNode("busy", 1, 1000, 1<<30, NewCpuResource(3900), NewMemoryResource(7<<30))
+	busy.CPUCapacity, busy.MemoryCapacity = NewCpuResource(4000), NewMemoryResource(8<<30)
+	unknown := testNode("unknown", 1, 1000, 1<<30, nil, nil)
+	unknown.CPUCapacity, unknown.MemoryCapacity = NewCpuResource(4000), NewMemoryResource(8<<30)
+	thresholds := Thresholds{Default: Threshold{Warning: 80, Critical: 95}}
+
+	// the usage is checked against the capacity, 97.5% of the cpu and 87.5% of the memory
+	got := violationColumns(NodeViolations([]NodeResource{busy, unknown}, thresholds, []string{ThresholdCPUUsage, ThresholdMemUsage}, LevelWarning))
+	want := []string{"busy:cpu-usage:critical", "busy:mem-usage:warning"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if names := UnknownNodeUsages([]NodeResource{busy, unknown}); len(names) != 1 || names[0] != "unknown" {
+		t.Errorf("got the usage of %v unknown, want the one of unknown", names)
+	}
+
+	cluster := ClusterResources([]NodeResource{busy})
+	got = violationColumns(ClusterViolations("cluster", cluster, thresholds, []string{ThresholdCPUUsage}, LevelCritical))
+	if len(got) != 1 || got[0] != "cluster:cpu-usage:critical" {
+		t.Errorf("got %v, want the cpu usage of the cluster critical", got)
+	}
+}
+
+func TestPodViolationsUsage(t *testing.T) {
+	pod := PodResource{Namespace: "default", Name: "web"}
+	pod.CPUUsages, pod.CPUUsagesFraction = NewCpuResource(450), 90
+	pending := PodResource{Namespace: "default", Name: "pending"}
+	thresholds := Thresholds{Default: Threshold{Warning: 80, Critical: 95}}
+
+	got := violationColumns(PodViolations([]PodResource{pod, pending}, thresholds, nil, LevelWarning))
+	if len(got) != 1 || got[0] != "web:cpu-usage:warning" {
+		t.Errorf("got %v, want the cpu usage of web at warning", got)
+	}
+	if names := UnknownPodUsages([]PodResource{pod, pending}); len(names) != 2 || names[0] != "default/web" || names[1] != "default/pending" {
+		t.Errorf("got the usage of %v unknown, want the ones of default/web, whose memory is unknown, and default/pending", names)
+	}
+}
@@ -0,0 +1,103 @@
+package kube
+
+// ClusterResource is the sum of the allocated resources of all nodes of a cluster.
+type ClusterResource struct {
+	// Nodes is the number of nodes summed up.
+	Nodes int `json:"nodes"`
+
+	NodeAllocatedResources
+
+	// CPUUsagesFraction is a fraction of CPU capacity, that is in use.
+	CPUUsagesFraction float64 `json:"cpuUsageFraction"`
+
+	// MemoryUsagesFraction is a fraction of memory capacity, that is in use.
+	MemoryUsagesFraction float64 `json:"memoryUsageFraction"`
+
+	// CPUOvercommit is the ratio of CPU limits to CPU capacity.
+	CPUOvercommit float64 `json:"cpuOvercommit"`
+
+	// MemoryOvercommit is the ratio of memory limits to memory capacity.
+	MemoryOvercommit float64 `json:"memoryOvercommit"`
+}
+
+// ClusterResources sums up the allocated resources of nodes. The usage is unknown if it is unknown on one
+// of the nodes, rather than the usage of part of the nodes against the capacity of all of them.
+func ClusterResources(noderesources []NodeResource) ClusterResource {
+	cpuUsages, memoryUsages := NewCpuResource(0), NewMemoryResource(0)
+	var cpuRequests, cpuLimits, cpuCapacity int64
+	var memoryRequests, memoryLimits, memoryCapacity int64
+	var gpuRequests, gpuLimits, gpuCapacity int64
+	var aliyunGpuMemRequests, aliyunGpuMemLimits, aliyunGpuMemCapacity int64
+	var allocatedPods int
+	var podCapacity int64
+	extended := ExtendedResources{}
+	ephemeralStorage, hugePages := newStorageResources(), newStorageResources()
+
+	for _, r := range noderesources {
+		cpuUsages = addCPUUsages(cpuUsages, r.CPUUsages)
+		cpuRequests += r.CPURequests.MilliValue()
+		cpuLimits += r.CPULimits.MilliValue()
+		cpuCapacity += r.CPUCapacity.MilliValue()
+		memoryUsages = addUsages(memoryUsages, r.MemoryUsages)
+		memoryRequests += r.MemoryRequests.Value()
+		memoryLimits += r.MemoryLimits.Value()
+		memoryCapacity += r.MemoryCapacity.Value()
+		gpuRequests += r.NvidiaGpuCountsRequests
+		gpuLimits += r.NvidiaGpuCountsLimits
+		gpuCapacity += r.NvidiaGpuCountsCapacity
+		aliyunGpuMemRequests += r.AliyunGpuMemRequests
+		aliyunGpuMemLimits += r.AliyunGpuMemLimits
+		aliyunGpuMemCapacity += r.AliyunGpuMemCapacity
+		allocatedPods += r.AllocatedPods
+		podCapacity += r.PodCapacity
+		extended = addExtendedResources(extended, r.ExtendedResources)
+		ephemeralStorage = addStorageResources(ephemeralStorage, r.EphemeralStorage)
+		hugePages = addStorageResources(hugePages, r.HugePages)
+	}
+
+	return ClusterResource{
+		Nodes: len(noderesources),
+		NodeAllocatedResources: NodeAllocatedResources{
+			CPUResources{
+				CPUUsages:           cpuUsages,
+				CPURequests:         NewCpuResource(cpuRequests),
+				CPURequestsFraction: calcPercentage(cpuRequests, cpuCapacity),
+				CPULimits:           NewCpuResource(cpuLimits),
+				CPULimitsFraction:   calcPercentage(cpuLimits, cpuCapacity),
+				CPUCapacity:         NewCpuResource(cpuCapacity),
+			},
+			MemoryResources{
+				MemoryUsages:           memoryUsages,
+				MemoryRequests:         NewMemoryResource(memoryRequests),
+				MemoryRequestsFraction: calcPercentage(memoryRequests, memoryCapacity),
+				MemoryLimits:           NewMemoryResource(memoryLimits),
+				MemoryLimitsFraction:   calcPercentage(memoryLimits, memoryCapacity),
+				MemoryCapacity:         NewMemoryResource(memoryCapacity),
+			},
+			GPUResources{
+				NvidiaGpuCountsRequests:         gpuRequests,
+				NvidiaGpuCountsRequestsFraction: calcPercentage(gpuRequests, gpuCapacity),
+				NvidiaGpuCountsLimits:           gpuLimits,
+				NvidiaGpuCountsLimitsFraction:   calcPercentage(gpuLimits, gpuCapacity),
+				NvidiaGpuCountsCapacity:         gpuCapacity,
+				AliyunGpuMemRequests:            aliyunGpuMemRequests,
+				AliyunGpuMemRequestsFraction:    calcPercentage(aliyunGpuMemRequests, aliyunGpuMemCapacity),
+				AliyunGpuMemLimits:              aliyunGpuMemLimits,
+				AliyunGpuMemLimitsFraction:      calcPercentage(aliyunGpuMemLimits, aliyunGpuMemCapacity),
+				AliyunGpuMemCapacity:            aliyunGpuMemCapacity,
+			},
+			PodResources{
+				AllocatedPods: allocatedPods,
+				PodCapacity:   podCapacity,
+				PodFraction:   calcPercentage(int64(allocatedPods), podCapacity),
+			},
+			extended,
+			ephemeralStorage,
+			hugePages,
+		},
+		CPUUsagesFraction:    calcPercentage(cpuUsages.MilliValue(), cpuCapacity),
+		MemoryUsagesFraction: calcPercentage(memoryUsages.Value(), memoryCapacity),
+		CPUOvercommit:        calcPercentage(cpuLimits, cpuCapacity) / 100,
+		MemoryOvercommit:     calcPercentage(memoryLimits, memoryCapacity) / 100,
+	}
+}
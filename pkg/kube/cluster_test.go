@@ -0,0 +1,53 @@
+package kube
+
+import "testing"
+
+func TestClusterResourcesUnknownUsage(t *testing.T) {
+	known := testNode("known", 1, 1000, 1<<30, NewCpuResource(2000), NewMemoryResource(4<<30))
+	known.CPUCapacity, known.MemoryCapacity = NewCpuResource(4000), NewMemoryResource(8<<30)
+	joined := testNode("joined", 0, 0, 0, nil, nil)
+	joined.CPUCapacity, joined.MemoryCapacity = NewCpuResource(4000), NewMemoryResource(8<<30)
+
+	cluster := ClusterResources([]NodeResource{known, known})
+	if cluster.CPUUsages.MilliValue() != 4000 || cluster.CPUUsagesFraction != 50 || cluster.MemoryUsagesFraction != 50 {
+		t.Errorf("got %s using %v%% of the cpu and %v%% of the memory, want 4000m using 50%% of both", cluster.CPUUsages, cluster.CPUUsagesFraction, cluster.MemoryUsagesFraction)
+	}
+
+	// the usage of one node would look like the usage of both
+	cluster = ClusterResources([]NodeResource{known, joined})
+	if cluster.CPUUsages != nil || cluster.MemoryUsages != nil {
+		t.Errorf("got the usage %s and %s with a node without metrics, want n/a", cluster.CPUUsages, cluster.MemoryUsages)
+	}
+	if cluster.CPURequests.MilliValue() != 1000 || cluster.CPUCapacity.MilliValue() != 8000 {
+		t.Errorf("got %s requested of %s, want 1000m of 8000m", cluster.CPURequests, cluster.CPUCapacity)
+	}
+}
+
+func TestNamespaceResourcesUnknownUsage(t *testing.T) {
+	running := PodResource{Namespace: "default", Name: "running"}
+	running.PodAllocatedResources = newPodAllocatedResources()
+	running.CPUUsages, running.MemoryUsages = NewCpuResource(100), NewMemoryResource(1<<20)
+	running.CPULimits = NewCpuResource(200)
+	pending := PodResource{Namespace: "default", Name: "pending"}
+	pending.PodAllocatedResources = newPodAllocatedResources()
+	pending.CPUUsages, pending.MemoryUsages = nil, nil
+	other := running
+	other.Namespace = "other"
+
+	namespaces := NamespaceResources([]PodResource{running, pending, other, other}, "")
+	if len(namespaces) != 2 {
+		t.Fatalf("got %d namespaces, want 2", len(namespaces))
+	}
+	if n := namespaces[0]; n.Name != "default" || n.Pods != 2 || n.CPUUsages != nil || n.MemoryUsages != nil {
+		t.Errorf("got %s of %d pods using %s and %s, want default of 2 pods using n/a", n.Name, n.Pods, n.CPUUsages, n.MemoryUsages)
+	}
+	if n := namespaces[1]; n.Name != "other" || n.CPUUsages.MilliValue() != 200 || n.CPUUsagesFraction != 50 {
+		t.Errorf("got %s using %s, %v%% of the limits, want other using 200m, 50%%", n.Name, n.CPUUsages, n.CPUUsagesFraction)
+	}
+	// the namespace of unknown usage is sorted last
+	for _, sortBy := range []string{"cpu", "memory"} {
+		if namespaces := NamespaceResources([]PodResource{pending, other}, sortBy); namespaces[0].Name != "other" {
+			t.Errorf("got %s first by %s, want other", namespaces[0].Name, sortBy)
+		}
+	}
+}
@@ -1,19 +1,14 @@
 package kube
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
-	"strings"
 
-	"github.com/logrusorgru/aurora/v3"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
-const (
-	warning_threshold  = 90.00
-	critical_threshold = 95.00
-)
 const (
 	// nvidia.com/gpu, number
 	ResourceNvidiaGpuCounts v1.ResourceName = "nvidia.com/gpu"
@@ -31,6 +26,10 @@ func NewGpuResource(name v1.ResourceName, rl *v1.ResourceList) *resource.Quantit
 	return rl.Name(name, resource.DecimalSI)
 }
 
+// NotAvailable is the text of a usage that is unknown, i.e. a nil CpuResource or MemoryResource,
+// e.g. without the metrics API.
+const NotAvailable = "n/a"
+
 //calcPercentage
 func calcPercentage(dividend, divisor int64) float64 {
 	if divisor > 0 {
@@ -54,7 +53,18 @@ func (r *MemoryResource) calcPercentage(divisor *resource.Quantity) float64 {
 	return calcPercentage(r.Value(), divisor.Value())
 }
 
+//Value returns the number of bytes, 0 if the memory is unknown
+func (r *MemoryResource) Value() int64 {
+	if r == nil || r.Quantity == nil {
+		return 0
+	}
+	return r.Quantity.Value()
+}
+
 func (r *MemoryResource) String() string {
+	if r == nil {
+		return NotAvailable
+	}
 	// XXX: Support more units
 	return fmt.Sprintf("%vMi", r.Value()/(1024*1024))
 }
@@ -64,6 +74,21 @@ func (r *MemoryResource) ToQuantity() *resource.Quantity {
 	return resource.NewQuantity(r.Value(), resource.BinarySI)
 }
 
+//MarshalJSON encodes the memory as a raw number of bytes
+func (r *MemoryResource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Value())
+}
+
+//UnmarshalJSON decodes the raw number of bytes of MarshalJSON, e.g. from a snapshot
+func (r *MemoryResource) UnmarshalJSON(data []byte) error {
+	var value int64
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	r.Quantity = resource.NewQuantity(value, resource.BinarySI)
+	return nil
+}
+
 type CpuResource struct {
 	*resource.Quantity
 }
@@ -74,42 +99,23 @@ func NewCpuResource(value int64) *CpuResource {
 	return &CpuResource{r}
 }
 
+//MilliValue returns the number of millicores, 0 if the cpu is unknown
+func (r *CpuResource) MilliValue() int64 {
+	if r == nil || r.Quantity == nil {
+		return 0
+	}
+	return r.Quantity.MilliValue()
+}
+
 //String
 func (r *CpuResource) String() string {
+	if r == nil {
+		return NotAvailable
+	}
 	// XXX: Support more units
 	return fmt.Sprintf("%vm", r.MilliValue())
 }
 
-//newFormat
-func newFormat(a string, b string) string {
-	return fmt.Sprintf("%s/%s", a, b)
-}
-
-//intToString int转string
-func intToString(a int) string {
-	str := strconv.Itoa(a)
-	return str
-}
-
-//float64ToString float64转string
-func float64ToString(s float64) string {
-	//return strconv.FormatFloat(s, 'G', -1, 32)
-	return fmt.Sprintf("%v%%", strconv.FormatFloat(s, 'G', -1, 64))
-
-}
-
-//int64ToString int64转string
-func int64ToString(a int64) string {
-	str := strconv.FormatInt(a, 10)
-	return str
-}
-
-//StringTofloat64
-func stringTofloat64(a string) float64 {
-	value, _ := strconv.ParseFloat(a, 64)
-	return value
-}
-
 //calcPercentage
 func (r *CpuResource) calcPercentage(divisor *resource.Quantity) float64 {
 	return calcPercentage(r.MilliValue(), divisor.MilliValue())
@@ -120,42 +126,26 @@ func (r *CpuResource) ToQuantity() *resource.Quantity {
 	return resource.NewMilliQuantity(r.MilliValue(), resource.DecimalSI)
 }
 
-
-//FieldString
-func FieldString(str string) float64 {
-	switch {
-	case strings.Contains(str, "%"):
-		str1 := strings.Split(str, "%")
-		value, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", stringTofloat64(str1[0])), 64)
-		return value
-	case strings.Contains(str, "Mi"):
-		str1 := strings.Split(str, "Mi")
-		value, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", stringTofloat64(str1[0])), 64)
-		return value
-	case strings.Contains(str, "m"):
-		str1 := strings.Split(str, "m")
-		value, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", stringTofloat64(str1[0])), 64)
-		return value
-	default:
-		return float64(0)
+//addCPUUsages sums two cpu usages which may be unknown, the sum is unknown if one of them is, as the
+//known part alone would look like a complete total
+func addCPUUsages(a, b *CpuResource) *CpuResource {
+	if a == nil || b == nil {
+		return nil
 	}
+	return NewCpuResource(a.MilliValue() + b.MilliValue())
 }
 
-//Compare
-func ExceedsCompare(a string) string {
-	if FieldString(a) > float64(critical_threshold) {
-		return redColor(a)
-	} else if FieldString(a) > float64(warning_threshold) {
-		return yellowColor(a)
-	} else {
-		return a
-	}
-}
-
-func redColor(s string) string {
-	return fmt.Sprintf("%s", aurora.Red(s))
+//MarshalJSON encodes the cpu as a raw number of millicores
+func (r *CpuResource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.MilliValue())
 }
 
-func yellowColor(s string) string {
-	return fmt.Sprintf("%s", aurora.Yellow(s))
+//UnmarshalJSON decodes the raw number of millicores of MarshalJSON, e.g. from a snapshot
+func (r *CpuResource) UnmarshalJSON(data []byte) error {
+	var value int64
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	r.Quantity = resource.NewMilliQuantity(value, resource.DecimalSI)
+	return nil
 }
@@ -0,0 +1,123 @@
+package kube
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// ExtendedResource is the allocation of an extended resource of a node.
+type ExtendedResource struct {
+	// Requests is the sum of the requests of the pods of the node.
+	Requests int64 `json:"requests"`
+
+	// RequestsFraction is a fraction of Capacity, that is requested.
+	RequestsFraction float64 `json:"requestsFraction"`
+
+	// Limits is the sum of the limits of the pods of the node.
+	Limits int64 `json:"limits"`
+
+	// LimitsFraction is a fraction of Capacity, that is limited, can be over 100%, i.e. overcommitted.
+	LimitsFraction float64 `json:"limitsFraction"`
+
+	// Capacity is the allocatable quantity of the node.
+	Capacity int64 `json:"capacity"`
+}
+
+// PodExtendedResource is the allocation of an extended resource of a pod or a container.
+type PodExtendedResource struct {
+	Requests int64 `json:"requests"`
+	Limits   int64 `json:"limits"`
+}
+
+// ExtendedResources maps the names of the extended resources of a node, e.g. amd.com/gpu or hugepages-2Mi,
+// to their allocation.
+type ExtendedResources map[string]ExtendedResource
+
+// PodExtendedResources maps the names of the extended resources of a pod to their allocation.
+type PodExtendedResources map[string]PodExtendedResource
+
+// IsExtendedResourceName reports whether name is a resource other than cpu, memory, pods and ephemeral-storage,
+// i.e. a device plugin resource such as amd.com/gpu or a hugepages size.
+func IsExtendedResourceName(name v1.ResourceName) bool {
+	switch name {
+	case v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods, v1.ResourceEphemeralStorage:
+		return false
+	}
+	return len(name) > 0
+}
+
+//getExtendedResources returns the allocation of every extended resource in the allocatable capacity of a node
+func getExtendedResources(reqs, limits, capacity v1.ResourceList) ExtendedResources {
+	resources := ExtendedResources{}
+	for name, quantity := range capacity {
+		if !IsExtendedResourceName(name) {
+			continue
+		}
+		requests, limit := reqs[name], limits[name]
+		resources[string(name)] = ExtendedResource{
+			Requests:         requests.Value(),
+			RequestsFraction: calcPercentage(requests.Value(), quantity.Value()),
+			Limits:           limit.Value(),
+			LimitsFraction:   calcPercentage(limit.Value(), quantity.Value()),
+			Capacity:         quantity.Value(),
+		}
+	}
+	return resources
+}
+
+//getPodExtendedResources returns the allocation of every extended resource requested or limited by a pod or container
+func getPodExtendedResources(reqs, limits v1.ResourceList) PodExtendedResources {
+	resources := PodExtendedResources{}
+	for _, list := range []v1.ResourceList{reqs, limits} {
+		for name := range list {
+			if !IsExtendedResourceName(name) {
+				continue
+			}
+			requests, limit := reqs[name], limits[name]
+			resources[string(name)] = PodExtendedResource{
+				Requests: requests.Value(),
+				Limits:   limit.Value(),
+			}
+		}
+	}
+	return resources
+}
+
+//addExtendedResources sums the extended resources of nodes and recalculates the fractions against the summed capacity
+func addExtendedResources(a, b ExtendedResources) ExtendedResources {
+	sum := ExtendedResources{}
+	for _, resources := range []ExtendedResources{a, b} {
+		for name, r := range resources {
+			s := sum[name]
+			s.Requests += r.Requests
+			s.Limits += r.Limits
+			s.Capacity += r.Capacity
+			s.RequestsFraction = calcPercentage(s.Requests, s.Capacity)
+			s.LimitsFraction = calcPercentage(s.Limits, s.Capacity)
+			sum[name] = s
+		}
+	}
+	return sum
+}
+
+//addPodExtendedResources sums the extended resources of pods
+func addPodExtendedResources(a, b PodExtendedResources) PodExtendedResources {
+	sum := PodExtendedResources{}
+	for _, resources := range []PodExtendedResources{a, b} {
+		for name, r := range resources {
+			s := sum[name]
+			s.Requests += r.Requests
+			s.Limits += r.Limits
+			sum[name] = s
+		}
+	}
+	return sum
+}
+
+//dividePodExtendedResources divides the extended resources of pods by n
+func dividePodExtendedResources(r PodExtendedResources, n int64) PodExtendedResources {
+	divided := PodExtendedResources{}
+	for name, e := range r {
+		divided[name] = PodExtendedResource{Requests: e.Requests / n, Limits: e.Limits / n}
+	}
+	return divided
+}
@@ -0,0 +1,110 @@
+package kube
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	nvidiaResourcePrefix = "nvidia.com/"
+	nvidiaMIGPrefix      = "nvidia.com/mig-"
+	nvidiaSharedSuffix   = ".shared"
+
+	// labels of the NVIDIA GPU feature discovery
+	nvidiaGPUCountLabel    = "nvidia.com/gpu.count"
+	nvidiaGPUProductLabel  = "nvidia.com/gpu.product"
+	nvidiaGPUReplicasLabel = "nvidia.com/gpu.replicas"
+)
+
+// Sharing modes of a GPU profile.
+const (
+	GPUSharingNone        = "none"
+	GPUSharingMIG         = "mig"
+	GPUSharingTimeSlicing = "time-slicing"
+)
+
+// GPUProfile is the allocation of a NVIDIA resource of a node, e.g. nvidia.com/gpu,
+// a MIG profile such as nvidia.com/mig-1g.5gb or a time-sliced nvidia.com/gpu.shared.
+type GPUProfile struct {
+	Resource string `json:"resource"`
+
+	// Sharing is how the physical GPUs are shared by the profile: none, mig or time-slicing.
+	Sharing string `json:"sharing"`
+
+	// PerGPU is the capacity of the profile per physical GPU, 0 if the number of GPUs is unknown.
+	PerGPU float64 `json:"perGPU"`
+
+	ExtendedResource
+}
+
+// NodeGPUResource is the allocation of the NVIDIA resources of a node.
+type NodeGPUResource struct {
+	Name string `json:"name"`
+
+	// GPUs is the number of physical GPUs from the nvidia.com/gpu.count label, 0 if the label is missing.
+	GPUs int64 `json:"gpus"`
+
+	// Product is the GPU model from the nvidia.com/gpu.product label.
+	Product string `json:"product,omitempty"`
+
+	Profiles []GPUProfile `json:"profiles"`
+}
+
+// NodeGPUResourceList is the structured output of the gpu command.
+type NodeGPUResourceList struct {
+	Items []NodeGPUResource `json:"items"`
+}
+
+// NodeGPUResources discovers the nvidia.com/* resources of every node and groups them under the
+// physical GPUs of the node. Nodes without any NVIDIA resource or label are left out, profiles are
+// ordered by name.
+func NodeGPUResources(nodes []NodeResource) []NodeGPUResource {
+	var gpus []NodeGPUResource
+	for _, node := range nodes {
+		count, _ := strconv.ParseInt(node.Labels[nvidiaGPUCountLabel], 10, 64)
+		replicas, _ := strconv.ParseInt(node.Labels[nvidiaGPUReplicasLabel], 10, 64)
+		g := NodeGPUResource{
+			Name:    node.Name,
+			GPUs:    count,
+			Product: node.Labels[nvidiaGPUProductLabel],
+		}
+		for name, r := range node.ExtendedResources {
+			if !strings.HasPrefix(name, nvidiaResourcePrefix) {
+				continue
+			}
+			profile := GPUProfile{
+				Resource:         name,
+				Sharing:          gpuSharing(name, replicas),
+				ExtendedResource: r,
+			}
+			if count > 0 {
+				profile.PerGPU = math.Round(float64(r.Capacity)/float64(count)*100) / 100
+			}
+			g.Profiles = append(g.Profiles, profile)
+		}
+		if len(g.Profiles) == 0 && count == 0 {
+			continue
+		}
+		sort.Slice(g.Profiles, func(i, j int) bool {
+			return g.Profiles[i].Resource < g.Profiles[j].Resource
+		})
+		gpus = append(gpus, g)
+	}
+	return gpus
+}
+
+//gpuSharing returns how the GPUs are shared by a NVIDIA resource. Time-slicing either advertises
+//nvidia.com/gpu.shared or, without renaming, nvidia.com/gpu with more than one replica per GPU.
+func gpuSharing(name string, replicas int64) string {
+	switch {
+	case strings.HasPrefix(name, nvidiaMIGPrefix):
+		return GPUSharingMIG
+	case strings.HasSuffix(name, nvidiaSharedSuffix):
+		return GPUSharingTimeSlicing
+	case name == string(ResourceNvidiaGpuCounts) && replicas > 1:
+		return GPUSharingTimeSlicing
+	}
+	return GPUSharingNone
+}
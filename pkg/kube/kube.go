@@ -7,14 +7,16 @@ import (
 	"sort"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/pager"
 
-	"k8s.io/kubectl/pkg/metricsutil"
 	metricsapi "k8s.io/metrics/pkg/apis/metrics"
 	metricsV1beta1api "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 
@@ -25,10 +27,17 @@ import (
 
 // KubeClient provides methods to get all required metrics from Kubernetes
 type KubeClient struct {
-	apiClient     *kubernetes.Clientset
-	metricsClient *metrics.Clientset
+	apiClient     kubernetes.Interface
+	metricsClient metrics.Interface
+	usage         UsageProvider
 }
 
+// listPageSize is the number of objects fetched per request when listing pods
+const listPageSize = 500
+
+// PodPhases are the phases a pod can be in, see ListPodResources.
+var PodPhases = []corev1.PodPhase{corev1.PodPending, corev1.PodRunning, corev1.PodSucceeded, corev1.PodFailed, corev1.PodUnknown}
+
 // NewClient creates a new client to get data from kubernetes masters
 func NewClient(config *rest.Config) (*KubeClient, error) {
 	// Add rate limiting configuration to avoid client-side throttling
@@ -49,9 +58,15 @@ func NewClient(config *rest.Config) (*KubeClient, error) {
 	return &KubeClient{
 		apiClient:     client,
 		metricsClient: metricsClient,
+		usage:         &metricsAPIUsage{metricsClient: metricsClient},
 	}, nil
 }
 
+// SetUsageProvider replaces the metrics API as the source of the usage of nodes and pods
+func (k *KubeClient) SetUsageProvider(usage UsageProvider) {
+	k.usage = usage
+}
+
 //GetNodes
 func (k *KubeClient) GetNodes(ctx context.Context, resourceName string, selector labels.Selector) (map[string]corev1.Node, error) {
 	nodes := make(map[string]corev1.Node)
@@ -79,316 +94,197 @@ func (k *KubeClient) GetNodes(ctx context.Context, resourceName string, selector
 	return nodes, nil
 }
 
-//GetActivePodByNodename
-func (k *KubeClient) GetActivePodByNodename(ctx context.Context, node corev1.Node) (*corev1.PodList, error) {
-	fieldSelector, err := fields.ParseSelector("spec.nodeName=" + node.Name +
-		",status.phase!=" + string(corev1.PodSucceeded) +
-		",status.phase!=" + string(corev1.PodFailed))
+//ListPods returns the pods matching opts, listing them in pages of listPageSize
+func (k *KubeClient) ListPods(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.Pod, error) {
+	return listPods(ctx, k.apiClient, namespace, opts)
+}
 
+//listPods returns the pods matching opts read with apiClient, listing them in pages of listPageSize
+func listPods(ctx context.Context, apiClient kubernetes.Interface, namespace string, opts metav1.ListOptions) ([]corev1.Pod, error) {
+	p := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return apiClient.CoreV1().Pods(namespace).List(ctx, opts)
+	})
+	p.PageSize = listPageSize
+
+	var pods []corev1.Pod
+	err := p.EachListItem(ctx, opts, func(obj runtime.Object) error {
+		pods = append(pods, *obj.(*corev1.Pod))
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	activePods, err := k.apiClient.CoreV1().Pods(corev1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector.String()})
+	return pods, nil
+}
+
+//GetActivePods returns the pods, that are neither succeeded nor failed, grouped by the name of their node.
+//An empty nodeName returns the active pods of all nodes.
+func (k *KubeClient) GetActivePods(ctx context.Context, nodeName string) (map[string][]corev1.Pod, error) {
+	selector := "status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed)
+	if len(nodeName) > 0 {
+		selector = "spec.nodeName=" + nodeName + "," + selector
+	}
+	fieldSelector, err := fields.ParseSelector(selector)
 	if err != nil {
 		return nil, err
 	}
-	return activePods, err
-}
 
-//GetActivePodByPodname
-func (k *KubeClient) GetPodByPodname(ctx context.Context, podName string, namespace string) (*corev1.Pod, error) {
-	pod, err := k.apiClient.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	pods, err := k.ListPods(ctx, corev1.NamespaceAll, metav1.ListOptions{FieldSelector: fieldSelector.String()})
 	if err != nil {
 		return nil, err
 	}
-	return pod, err
+
+	podsByNodeName := make(map[string][]corev1.Pod)
+	for _, pod := range pods {
+		podsByNodeName[pod.Spec.NodeName] = append(podsByNodeName[pod.Spec.NodeName], pod)
+	}
+	return podsByNodeName, nil
 }
 
-//NodeResources
-func (k *KubeClient) GetNodeResources(ctx context.Context, resourceName string, resourceType []string, sortBy string, selector labels.Selector) ([][]string, error) {
+//GetNodeResources returns the allocated resources of every node ordered by name, with the node metrics left joined
+//by node name. The usage of the nodes without metrics, e.g. not ready or not scraped yet, is unknown.
+//Nodes, node metrics and active pods are each listed once.
+func (k *KubeClient) GetNodeResources(ctx context.Context, resourceName string, selector labels.Selector) ([]NodeResource, error) {
 	metrics, err := k.GetNodeMetricsFromMetricsAPI(ctx, resourceName, selector)
-	if err != nil {
+	if err != nil && !apierrors.IsNotFound(err) {
 		return nil, err
 	}
-
-	if len(sortBy) > 0 {
-		sorter := metricsutil.NewNodeMetricsSorter(metrics.Items, sortBy)
-		if sorter != nil {
-			sort.Sort(sorter)
+	nodemetricsByName := make(map[string]*metricsapi.NodeMetrics)
+	if metrics != nil {
+		for i := range metrics.Items {
+			nodemetricsByName[metrics.Items[i].Name] = &metrics.Items[i]
 		}
 	}
+	return k.listNodeResources(ctx, resourceName, selector, nodemetricsByName)
+}
 
-	var nodenames []string
-	for _, i := range metrics.Items {
-		nodenames = append(nodenames, i.Name)
-	}
+//GetNodeResourcesWithoutUsage returns the allocated resources of every node ordered by name, leaving the usage
+//unknown. Only the core API is used, for clusters without the metrics API.
+func (k *KubeClient) GetNodeResourcesWithoutUsage(ctx context.Context, resourceName string, selector labels.Selector) ([]NodeResource, error) {
+	return k.listNodeResources(ctx, resourceName, selector, nil)
+}
 
+//listNodeResources returns the allocated resources of the nodes ordered by name, with the usage of nodemetricsByName
+func (k *KubeClient) listNodeResources(ctx context.Context, resourceName string, selector labels.Selector, nodemetricsByName map[string]*metricsapi.NodeMetrics) ([]NodeResource, error) {
 	nodes, err := k.GetNodes(ctx, resourceName, selector)
 	if err != nil {
 		return nil, err
 	}
 
-	// 使用 map 来保存结果，键为节点名称
-	resultMap := make(map[string][]string)
-	
-	// Create channels for results and errors
-	type nodeResult struct {
-		nodeName string
-		resource []string
-		err      error
-	}
-	resultChan := make(chan nodeResult, len(nodenames))
-
-	// Process nodes concurrently
-	for _, nodename := range nodenames {
-		go func(nodename string) {
-			// Check context before starting work
-			select {
-			case <-ctx.Done():
-				resultChan <- nodeResult{nodename, nil, ctx.Err()}
-				return
-			default:
-			}
-
-			var resource []string
-			
-			// Get active pods with context
-			activePodsList, err := k.GetActivePodByNodename(ctx, nodes[nodename])
-			if err != nil {
-				resultChan <- nodeResult{nodename, nil, err}
-				return
-			}
-
-			// Get node metrics with context
-			NodeMetricsList, err := k.GetNodeMetricsFromMetricsAPI(ctx, resourceName, selector)
-			if err != nil {
-				resultChan <- nodeResult{nodename, nil, err}
-				return
-			}
-
-			resource = append(resource, nodename)
-			for _, t := range resourceType {
-				// Check context periodically
-				select {
-				case <-ctx.Done():
-					resultChan <- nodeResult{nodename, nil, ctx.Err()}
-					return
-				default:
-				}
-
-				noderesource, err := getNodeAllocatedResources(nodes[nodename], activePodsList, NodeMetricsList, t)
-				if err != nil {
-					log.Printf("Couldn't get allocated resources of %s node: %s\n", nodename, err)
-					continue
-				}
-
-				switch {
-				case t == "cpu":
-					resource = append(resource,
-						noderesource.CPUUsages.String(),
-						newFormat(noderesource.CPURequests.String(), noderesource.CPUCapacity.String()),
-						ExceedsCompare(float64ToString(noderesource.CPURequestsFraction)),
-						newFormat(noderesource.CPULimits.String(), noderesource.CPUCapacity.String()),
-						float64ToString(noderesource.CPULimitsFraction),
-					)
-				case t == "memory":
-					resource = append(resource,
-						noderesource.MemoryUsages.String(),
-						newFormat(noderesource.MemoryRequests.String(), noderesource.MemoryCapacity.String()), ExceedsCompare(float64ToString(noderesource.MemoryRequestsFraction)),
-						newFormat(noderesource.MemoryLimits.String(), noderesource.MemoryCapacity.String()), float64ToString(noderesource.MemoryLimitsFraction),
-					)
-				case t == "gpu":
-					// resource = append(resource,
-					// 	newFormat(int64ToString(noderesource.NvidiaGpuCountsRequests), int64ToString(noderesource.NvidiaGpuCountsCapacity)), ExceedsCompare(float64ToString(noderesource.NvidiaGpuCountsRequestsFraction)),
-					// 	newFormat(int64ToString(noderesource.NvidiaGpuCountsLimits), int64ToString(noderesource.NvidiaGpuCountsCapacity)), float64ToString(noderesource.NvidiaGpuCountsLimitsFraction),
-					// 	newFormat(int64ToString(noderesource.AliyunGpuMemRequests), int64ToString(noderesource.AliyunGpuMemCapacity)), ExceedsCompare(float64ToString(noderesource.AliyunGpuMemRequestsFraction)),
-					// 	newFormat(int64ToString(noderesource.AliyunGpuMemLimits), int64ToString(noderesource.AliyunGpuMemCapacity)), float64ToString(noderesource.AliyunGpuMemLimitsFraction),
-					// )
-					resource = append(resource,
-						newFormat(int64ToString(noderesource.NvidiaGpuCountsRequests), int64ToString(noderesource.NvidiaGpuCountsCapacity)), ExceedsCompare(float64ToString(noderesource.NvidiaGpuCountsRequestsFraction)),
-						newFormat(int64ToString(noderesource.NvidiaGpuCountsLimits), int64ToString(noderesource.NvidiaGpuCountsCapacity)), float64ToString(noderesource.NvidiaGpuCountsLimitsFraction),
-					)
-				case t == "pod":
-					resource = append(resource,
-						newFormat(intToString(noderesource.AllocatedPods), int64ToString(noderesource.PodCapacity)), ExceedsCompare(float64ToString(noderesource.PodFraction)),
-					)
-				default:
-					// resource = append(resource,
-					// 	noderesource.CPUUsages.String(),
-					// 	newFormat(noderesource.CPURequests.String(), noderesource.CPUCapacity.String()), ExceedsCompare(float64ToString(noderesource.CPURequestsFraction)),
-					// 	newFormat(noderesource.CPULimits.String(), noderesource.CPUCapacity.String()), float64ToString(noderesource.CPULimitsFraction),
-					// 	noderesource.MemoryUsages.String(),
-					// 	newFormat(noderesource.MemoryRequests.String(), noderesource.MemoryCapacity.String()), ExceedsCompare(float64ToString(noderesource.MemoryRequestsFraction)),
-					// 	newFormat(noderesource.MemoryLimits.String(), noderesource.MemoryCapacity.String()), float64ToString(noderesource.MemoryLimitsFraction),
-					// 	newFormat(int64ToString(noderesource.NvidiaGpuCountsRequests), int64ToString(noderesource.NvidiaGpuCountsCapacity)), ExceedsCompare(float64ToString(noderesource.NvidiaGpuCountsRequestsFraction)),
-					// 	newFormat(int64ToString(noderesource.NvidiaGpuCountsLimits), int64ToString(noderesource.NvidiaGpuCountsCapacity)), float64ToString(noderesource.NvidiaGpuCountsLimitsFraction),
-					// 	newFormat(int64ToString(noderesource.AliyunGpuMemRequests), int64ToString(noderesource.AliyunGpuMemCapacity)), ExceedsCompare(float64ToString(noderesource.AliyunGpuMemRequestsFraction)),
-					// 	newFormat(int64ToString(noderesource.AliyunGpuMemLimits), int64ToString(noderesource.AliyunGpuMemCapacity)), float64ToString(noderesource.AliyunGpuMemLimitsFraction),
-					// 	newFormat(intToString(noderesource.AllocatedPods), int64ToString(noderesource.PodCapacity)), ExceedsCompare(float64ToString(noderesource.PodFraction)),
-					// )
-					resource = append(resource,
-						noderesource.CPUUsages.String(),
-						newFormat(noderesource.CPURequests.String(), noderesource.CPUCapacity.String()), ExceedsCompare(float64ToString(noderesource.CPURequestsFraction)),
-						newFormat(noderesource.CPULimits.String(), noderesource.CPUCapacity.String()), float64ToString(noderesource.CPULimitsFraction),
-						noderesource.MemoryUsages.String(),
-						newFormat(noderesource.MemoryRequests.String(), noderesource.MemoryCapacity.String()), ExceedsCompare(float64ToString(noderesource.MemoryRequestsFraction)),
-						newFormat(noderesource.MemoryLimits.String(), noderesource.MemoryCapacity.String()), float64ToString(noderesource.MemoryLimitsFraction),
-						newFormat(int64ToString(noderesource.NvidiaGpuCountsRequests), int64ToString(noderesource.NvidiaGpuCountsCapacity)), ExceedsCompare(float64ToString(noderesource.NvidiaGpuCountsRequestsFraction)),
-						newFormat(int64ToString(noderesource.NvidiaGpuCountsLimits), int64ToString(noderesource.NvidiaGpuCountsCapacity)), float64ToString(noderesource.NvidiaGpuCountsLimitsFraction),
-						newFormat(intToString(noderesource.AllocatedPods), int64ToString(noderesource.PodCapacity)), ExceedsCompare(float64ToString(noderesource.PodFraction)),
-					)
-				}
-			}
-			resultChan <- nodeResult{nodename, resource, nil}
-		}(nodename)
+	podsByNodeName, err := k.GetActivePods(ctx, resourceName)
+	if err != nil {
+		return nil, err
 	}
 
-	// Collect results with context awareness
-	var firstError error
-	for i := 0; i < len(nodenames); i++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case result := <-resultChan:
-			if result.err != nil {
-				if firstError == nil {
-					firstError = result.err
-				}
-				continue
-			}
-			if result.resource != nil {
-				resultMap[result.nodeName] = result.resource
-			}
-		}
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	// 按照原始排序顺序重建结果数组
-	var resources [][]string
-	for _, nodeName := range nodenames {
-		if resource, ok := resultMap[nodeName]; ok {
-			resources = append(resources, resource)
+	var resources []NodeResource
+	for _, name := range names {
+		if noderesource, ok := nodeResource(nodes[name], podsByNodeName[name], nodemetricsByName[name]); ok {
+			resources = append(resources, noderesource)
 		}
 	}
-
-	if firstError != nil {
-		return nil, firstError
-	}
 	return resources, nil
 }
 
-func (k *KubeClient) GetPodResources(ctx context.Context, podmetrics []metricsapi.PodMetrics, namespace string, resourceName string, allNamespaces bool, resourceType []string, sortBy string, labelSelector labels.Selector, fieldSelector fields.Selector) ([][]string, error) {
-	if len(sortBy) > 0 {
-		sorter := metricsutil.NewPodMetricsSorter(podmetrics, allNamespaces, sortBy)
-		if sorter != nil {
-			sort.Sort(sorter)
-		}
+//nodeResource returns the allocated resources of node, logging the nodes whose resources cannot be computed
+func nodeResource(node corev1.Node, pods []corev1.Pod, nodemetrics *metricsapi.NodeMetrics) (NodeResource, bool) {
+	noderesource, err := getNodeAllocatedResources(node, pods, nodemetrics)
+	if err != nil {
+		log.Printf("Couldn't get allocated resources of %s node: %s\n", node.Name, err)
+		return NodeResource{}, false
 	}
+	return NodeResource{Name: node.Name, Labels: node.Labels, NodeAllocatedResources: noderesource}, true
+}
 
-	// 使用 map 来保存结果，键为 pod 的唯一标识符
-	resultMap := make(map[string][]string)
-	
-	type podResult struct {
-		podKey string // namespace/name
-		resource []string
-		err      error
+//ListPodResources returns the allocated resources of the pods matching the selectors, or of the pod named
+//resourceName, in the order of the core API. Only the pods in one of phases are listed, the pods that are neither
+//succeeded nor failed if phases is empty. With usage, the pod metrics are left joined by namespace and name and
+//the usage of the pods without metrics, e.g. pending or not scraped yet, is unknown. Without usage, only the
+//core API is used, for clusters without the metrics API.
+func (k *KubeClient) ListPodResources(ctx context.Context, namespace, resourceName string, allNamespaces bool, labelSelector labels.Selector, fieldSelector fields.Selector, phases []corev1.PodPhase, usage bool) ([]PodResource, error) {
+	ns := metav1.NamespaceAll
+	if !allNamespaces {
+		ns = namespace
+	}
+	selectors := phaseSelectors(phases)
+	if !fieldSelector.Empty() {
+		selectors = append(selectors, fieldSelector)
+	}
+	if len(resourceName) > 0 {
+		selectors = append(selectors, fields.OneTermEqualSelector("metadata.name", resourceName))
+	}
+	pods, err := k.ListPods(ctx, ns, metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+		FieldSelector: fields.AndSelectors(selectors...).String(),
+	})
+	if err != nil {
+		return nil, err
 	}
-	resultChan := make(chan podResult, len(podmetrics))
-
-	// 修改并发处理以包含 pod 标识符
-	for _, podmetric := range podmetrics {
-		go func(podmetric metricsapi.PodMetrics) {
-			podKey := podmetric.Namespace + "/" + podmetric.Name
-			// Check context before starting work
-			select {
-			case <-ctx.Done():
-				resultChan <- podResult{podKey, nil, ctx.Err()}
-				return
-			default:
-			}
-
-			var resource []string
-			pod, err := k.GetPodByPodname(ctx, podmetric.Name, podmetric.Namespace)
-			if err != nil {
-				resultChan <- podResult{podKey, nil, err}
-				return
-			}
 
-			resource = append(resource, podmetric.Namespace, podmetric.Name)
-			for _, t := range resourceType {
-				// Check context periodically during processing
-				select {
-				case <-ctx.Done():
-					resultChan <- podResult{podKey, nil, ctx.Err()}
-					return
-				default:
-				}
-
-				podresource, err := getPodAllocatedResources(pod, &podmetric, t)
-				if err != nil {
-					resultChan <- podResult{podKey, nil, err}
-					return
-				}
-				switch {
-				case t == "cpu":
-					resource = append(resource,
-						podresource.CPUUsages.String(), ExceedsCompare(float64ToString(podresource.CPUUsagesFraction)),
-						podresource.CPURequests.String(), podresource.CPULimits.String(),
-					)
-				case t == "memory":
-					resource = append(resource,
-						podresource.MemoryUsages.String(), ExceedsCompare(float64ToString(podresource.MemoryUsagesFraction)),
-						podresource.MemoryRequests.String(), podresource.MemoryLimits.String(),
-					)
-				case t == "gpu":
-					resource = append(resource,
-						int64ToString(podresource.NvidiaGpuCountsRequests), int64ToString(podresource.NvidiaGpuCountsLimits),
-					)
-				default:
-					resource = append(resource,
-						podresource.CPUUsages.String(), ExceedsCompare(float64ToString(podresource.CPUUsagesFraction)),
-						podresource.CPURequests.String(), podresource.CPULimits.String(),
-						podresource.MemoryUsages.String(), ExceedsCompare(float64ToString(podresource.MemoryUsagesFraction)),
-						podresource.MemoryRequests.String(), podresource.MemoryLimits.String(),
-						int64ToString(podresource.NvidiaGpuCountsRequests), int64ToString(podresource.NvidiaGpuCountsLimits),
-					)
-				}
+	var podmetricsByKey map[string]*metricsapi.PodMetrics
+	if usage && len(pods) > 0 {
+		metrics, err := k.GetPodMetricsFromMetricsAPI(ctx, namespace, resourceName, allNamespaces, labelSelector, fields.Everything())
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		podmetricsByKey = make(map[string]*metricsapi.PodMetrics)
+		if metrics != nil {
+			for i := range metrics.Items {
+				podmetricsByKey[metrics.Items[i].Namespace+"/"+metrics.Items[i].Name] = &metrics.Items[i]
 			}
-			resultChan <- podResult{podKey, resource, nil}
-		}(podmetric)
+		}
 	}
 
-	// 收集结果到 map
-	var firstError error
-	for i := 0; i < len(podmetrics); i++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case result := <-resultChan:
-			if result.err != nil {
-				if firstError == nil {
-					firstError = result.err
-				}
-				continue
-			}
-			if result.resource != nil {
-				resultMap[result.podKey] = result.resource
-			}
+	var resources []PodResource
+	for i := range pods {
+		podresource, err := podResource(&pods[i], podmetricsByKey[pods[i].Namespace+"/"+pods[i].Name])
+		if err != nil {
+			return nil, err
 		}
+		resources = append(resources, podresource)
 	}
+	return resources, nil
+}
 
-	// 按照原始排序顺序重建结果数组
-	var resources [][]string
-	for _, podmetric := range podmetrics {
-		podKey := podmetric.Namespace + "/" + podmetric.Name
-		if resource, ok := resultMap[podKey]; ok {
-			resources = append(resources, resource)
+//phaseSelectors returns the field selectors excluding the pods in any other phase than phases,
+//or in the succeeded and failed phases if phases is empty
+func phaseSelectors(phases []corev1.PodPhase) []fields.Selector {
+	included := map[corev1.PodPhase]bool{}
+	for _, phase := range phases {
+		included[phase] = true
+	}
+	if len(phases) == 0 {
+		included = map[corev1.PodPhase]bool{corev1.PodPending: true, corev1.PodRunning: true, corev1.PodUnknown: true}
+	}
+	var selectors []fields.Selector
+	for _, phase := range PodPhases {
+		if !included[phase] {
+			selectors = append(selectors, fields.OneTermNotEqualSelector("status.phase", string(phase)))
 		}
 	}
+	return selectors
+}
 
-	if firstError != nil {
-		return nil, firstError
+//podResource returns the allocated resources of pod and its containers, a nil podmetric leaves the usage unknown
+func podResource(pod *corev1.Pod, podmetric *metricsapi.PodMetrics) (PodResource, error) {
+	podresource, err := getPodAllocatedResources(pod, podmetric)
+	if err != nil {
+		return PodResource{}, err
 	}
-	return resources, nil
+	return PodResource{
+		Namespace:             pod.Namespace,
+		Name:                  pod.Name,
+		NodeName:              pod.Spec.NodeName,
+		Phase:                 pod.Status.Phase,
+		Controller:            metav1.GetControllerOf(pod),
+		PodAllocatedResources: podresource,
+		Containers:            getContainerAllocatedResources(pod, podmetric),
+	}, nil
 }
 
 // PodMetricses returns all pods' usage metrics
@@ -401,63 +297,12 @@ func (k *KubeClient) PodMetricses(ctx context.Context) (*metricsV1beta1api.PodMe
 	return podMetricses, nil
 }
 
-// GetNodeMetricsFromMetricsAPI with context
+// GetNodeMetricsFromMetricsAPI returns the usage of the nodes from the usage provider, the metrics API by default
 func (k *KubeClient) GetNodeMetricsFromMetricsAPI(ctx context.Context, resourceName string, selector labels.Selector) (*metricsapi.NodeMetricsList, error) {
-	var err error
-	versionedMetrics := &metricsV1beta1api.NodeMetricsList{}
-	mc := k.metricsClient.MetricsV1beta1()
-	nm := mc.NodeMetricses()
-	
-	if resourceName != "" {
-		m, err := nm.Get(ctx, resourceName, metav1.GetOptions{})
-		if err != nil {
-			return nil, err
-		}
-		versionedMetrics.Items = []metricsV1beta1api.NodeMetrics{*m}
-	} else {
-		versionedMetrics, err = nm.List(ctx, metav1.ListOptions{
-			LabelSelector: selector.String(),
-		})
-		if err != nil {
-			return nil, err
-		}
-	}
-	
-	metrics := &metricsapi.NodeMetricsList{}
-	err = metricsV1beta1api.Convert_v1beta1_NodeMetricsList_To_metrics_NodeMetricsList(versionedMetrics, metrics, nil)
-	if err != nil {
-		return nil, err
-	}
-	return metrics, nil
+	return k.usage.NodeMetrics(ctx, resourceName, selector)
 }
 
-// GetPodMetricsFromMetricsAPI
+// GetPodMetricsFromMetricsAPI returns the usage of the pods from the usage provider, the metrics API by default
 func (k *KubeClient) GetPodMetricsFromMetricsAPI(ctx context.Context, namespace, resourceName string, allNamespaces bool, labelSelector labels.Selector, fieldSelector fields.Selector) (*metricsapi.PodMetricsList, error) {
-	var err error
-	ns := metav1.NamespaceAll
-	if !allNamespaces {
-		ns = namespace
-	}
-	versionedMetrics := &metricsV1beta1api.PodMetricsList{}
-	if resourceName != "" {
-		m, err := k.metricsClient.MetricsV1beta1().PodMetricses(ns).Get(ctx, resourceName, metav1.GetOptions{})
-		if err != nil {
-			return nil, err
-		}
-		versionedMetrics.Items = []metricsV1beta1api.PodMetrics{*m}
-	} else {
-		versionedMetrics, err = k.metricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{
-			LabelSelector: labelSelector.String(), 
-			FieldSelector: fieldSelector.String(),
-		})
-		if err != nil {
-			return nil, err
-		}
-	}
-	metrics := &metricsapi.PodMetricsList{}
-	err = metricsV1beta1api.Convert_v1beta1_PodMetricsList_To_metrics_PodMetricsList(versionedMetrics, metrics, nil)
-	if err != nil {
-		return nil, err
-	}
-	return metrics, nil
+	return k.usage.PodMetrics(ctx, namespace, resourceName, allNamespaces, labelSelector, fieldSelector)
 }
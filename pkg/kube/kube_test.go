@@ -0,0 +1,185 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// podsPerNode is the number of pods scheduled on every node of newFakeCluster
+const podsPerNode = 10
+
+// fakeCluster is a fake core API and metrics API of nodes running podsPerNode pods each
+type fakeCluster struct {
+	apiClient     *fake.Clientset
+	metricsClient *metricsfake.Clientset
+}
+
+//newFakeCluster returns a fake cluster of n nodes with the metrics of every node and pod
+func newFakeCluster(n int) *fakeCluster {
+	var objects []runtime.Object
+	nodeMetrics := &metricsv1beta1.NodeMetricsList{}
+	podMetrics := &metricsv1beta1.PodMetricsList{}
+	for i := 0; i < n; i++ {
+		node := fakeNode(fmt.Sprintf("node-%d", i), "4", "8Gi")
+		objects = append(objects, node)
+		nodeMetrics.Items = append(nodeMetrics.Items, metricsv1beta1.NodeMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: node.Name},
+			Usage:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+		})
+		for j := 0; j < podsPerNode; j++ {
+			pod := fakePod("default", fmt.Sprintf("pod-%d-%d", i, j), node.Name, "100m", "128Mi")
+			objects = append(objects, pod)
+			podMetrics.Items = append(podMetrics.Items, metricsv1beta1.PodMetrics{
+				ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+				Containers: []metricsv1beta1.ContainerMetrics{{
+					Name:  "app",
+					Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+				}},
+			})
+		}
+	}
+
+	// the object tracker of the fake metrics clientset does not map the metrics kinds to their resources,
+	// the lists are served by reactors instead
+	metricsClient := &metricsfake.Clientset{}
+	metricsClient.AddReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nodeMetrics.DeepCopy(), nil
+	})
+	metricsClient.AddReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, podMetrics.DeepCopy(), nil
+	})
+	return &fakeCluster{apiClient: fake.NewSimpleClientset(objects...), metricsClient: metricsClient}
+}
+
+//client returns a KubeClient reading the usage from the metrics API of the fake cluster
+func (c *fakeCluster) client() *KubeClient {
+	return &KubeClient{
+		apiClient:     c.apiClient,
+		metricsClient: c.metricsClient,
+		usage:         &metricsAPIUsage{metricsClient: c.metricsClient},
+	}
+}
+
+//clearActions forgets the API calls recorded so far
+func (c *fakeCluster) clearActions() {
+	c.apiClient.ClearActions()
+	c.metricsClient.ClearActions()
+}
+
+//calls counts the API calls recorded by verb and resource, e.g. "list pods", metrics API calls prefixed by metrics
+func (c *fakeCluster) calls() map[string]int {
+	calls := map[string]int{}
+	for _, a := range c.apiClient.Actions() {
+		calls[a.GetVerb()+" "+a.GetResource().Resource]++
+	}
+	for _, a := range c.metricsClient.Actions() {
+		calls["metrics "+a.GetVerb()+" "+a.GetResource().Resource]++
+	}
+	return calls
+}
+
+//assertCalls fails b unless the recorded API calls are exactly want
+func (c *fakeCluster) assertCalls(b testing.TB, want map[string]int) {
+	b.Helper()
+	calls := c.calls()
+	if len(calls) != len(want) {
+		b.Fatalf("API calls = %v, want %v", calls, want)
+	}
+	for call, n := range want {
+		if calls[call] != n {
+			b.Fatalf("API calls = %v, want %v", calls, want)
+		}
+	}
+}
+
+//fakeNode returns a ready node with the given allocatable cpu and memory
+func fakeNode(name, cpu, memory string) *corev1.Node {
+	allocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     corev1.NodeStatus{Capacity: allocatable, Allocatable: allocatable},
+	}
+}
+
+//fakePod returns a running pod of a single container requesting and limited to cpu and memory
+func fakePod(namespace, name, nodeName, cpu, memory string) *corev1.Pod {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name:      "app",
+				Resources: corev1.ResourceRequirements{Requests: resources, Limits: resources},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// BenchmarkGetNodeResources checks that the nodes, their pods and their metrics are each listed once,
+// whatever the number of nodes.
+func BenchmarkGetNodeResources(b *testing.B) {
+	for _, n := range []int{10, 100, 800} {
+		b.Run(fmt.Sprintf("nodes=%d", n), func(b *testing.B) {
+			cluster := newFakeCluster(n)
+			k := cluster.client()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cluster.clearActions()
+				nodes, err := k.GetNodeResources(context.Background(), "", labels.Everything())
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(nodes) != n {
+					b.Fatalf("got %d nodes, want %d", len(nodes), n)
+				}
+				cluster.assertCalls(b, map[string]int{"list nodes": 1, "list pods": 1, "metrics list nodes": 1})
+			}
+			b.ReportMetric(float64(len(cluster.apiClient.Actions())+len(cluster.metricsClient.Actions())), "calls/op")
+		})
+	}
+}
+
+// BenchmarkListPodResources checks that the pods and their metrics are each listed once, whatever the
+// number of pods.
+func BenchmarkListPodResources(b *testing.B) {
+	for _, n := range []int{10, 100, 800} {
+		b.Run(fmt.Sprintf("nodes=%d", n), func(b *testing.B) {
+			cluster := newFakeCluster(n)
+			k := cluster.client()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cluster.clearActions()
+				pods, err := k.ListPodResources(context.Background(), "", "", true, labels.Everything(), fields.Everything(), nil, true)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(pods) != n*podsPerNode {
+					b.Fatalf("got %d pods, want %d", len(pods), n*podsPerNode)
+				}
+				cluster.assertCalls(b, map[string]int{"list pods": 1, "metrics list pods": 1})
+			}
+			b.ReportMetric(float64(len(cluster.apiClient.Actions())+len(cluster.metricsClient.Actions())), "calls/op")
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package kube
+
+import (
+	"sort"
+)
+
+// NamespaceResource is the sum of the allocated resources of all pods in a namespace.
+type NamespaceResource struct {
+	Name string `json:"name"`
+
+	// Pods is the number of pods summed up in the namespace.
+	Pods int `json:"pods"`
+
+	PodAllocatedResources
+}
+
+// NamespaceResourceList is the structured output of the namespace command.
+type NamespaceResourceList struct {
+	Items []NamespaceResource `json:"items"`
+}
+
+// NamespaceResources rolls up pod resources per namespace. Namespaces are ordered
+// by name, or by descending usage when sortBy is cpu or memory, an unknown usage last.
+func NamespaceResources(podresources []PodResource, sortBy string) []NamespaceResource {
+	index := make(map[string]int)
+	var namespaces []NamespaceResource
+	for _, podresource := range podresources {
+		i, ok := index[podresource.Namespace]
+		if !ok {
+			i = len(namespaces)
+			index[podresource.Namespace] = i
+			namespaces = append(namespaces, NamespaceResource{
+				Name:                  podresource.Namespace,
+				PodAllocatedResources: newPodAllocatedResources(),
+			})
+		}
+		namespaces[i].Pods++
+		namespaces[i].PodAllocatedResources = addPodAllocatedResources(namespaces[i].PodAllocatedResources, podresource.PodAllocatedResources)
+	}
+
+	sort.SliceStable(namespaces, func(i, j int) bool {
+		switch sortBy {
+		case "cpu":
+			a, b := namespaces[i].CPUUsages, namespaces[j].CPUUsages
+			if (a == nil) != (b == nil) {
+				return b == nil
+			}
+			return a.MilliValue() > b.MilliValue()
+		case "memory":
+			a, b := namespaces[i].MemoryUsages, namespaces[j].MemoryUsages
+			if (a == nil) != (b == nil) {
+				return b == nil
+			}
+			return a.Value() > b.Value()
+		default:
+			return namespaces[i].Name < namespaces[j].Name
+		}
+	})
+	return namespaces
+}
+
+// newPodAllocatedResources returns zero valued pod resources that can be added to.
+func newPodAllocatedResources() PodAllocatedResources {
+	return PodAllocatedResources{
+		PodCPUResources{
+			CPUUsages:   NewCpuResource(0),
+			CPURequests: NewCpuResource(0),
+			CPULimits:   NewCpuResource(0),
+		},
+		PodMemoryResources{
+			MemoryUsages:   NewMemoryResource(0),
+			MemoryRequests: NewMemoryResource(0),
+			MemoryLimits:   NewMemoryResource(0),
+		},
+		PodGPUResources{},
+		PodExtendedResources{},
+		PodStorageResources{Usages: NewMemoryResource(0), Requests: NewMemoryResource(0), Limits: NewMemoryResource(0)},
+		PodStorageResources{Usages: NewMemoryResource(0), Requests: NewMemoryResource(0), Limits: NewMemoryResource(0)},
+	}
+}
+
+// addPodAllocatedResources sums a and b and recalculates the usage fractions against the summed limits.
+// The usage is unknown if it is unknown in one of them.
+func addPodAllocatedResources(a, b PodAllocatedResources) PodAllocatedResources {
+	cpuUsages := addCPUUsages(a.CPUUsages, b.CPUUsages)
+	cpuLimits := NewCpuResource(a.CPULimits.MilliValue() + b.CPULimits.MilliValue())
+	memoryUsages := addUsages(a.MemoryUsages, b.MemoryUsages)
+	memoryLimits := NewMemoryResource(a.MemoryLimits.Value() + b.MemoryLimits.Value())
+
+	return PodAllocatedResources{
+		PodCPUResources{
+			CPUUsages:         cpuUsages,
+			CPUUsagesFraction: cpuUsages.calcPercentage(cpuLimits.Quantity),
+			CPURequests:       NewCpuResource(a.CPURequests.MilliValue() + b.CPURequests.MilliValue()),
+			CPULimits:         cpuLimits,
+		},
+		PodMemoryResources{
+			MemoryUsages:         memoryUsages,
+			MemoryUsagesFraction: memoryUsages.calcPercentage(memoryLimits.Quantity),
+			MemoryRequests:       NewMemoryResource(a.MemoryRequests.Value() + b.MemoryRequests.Value()),
+			MemoryLimits:         memoryLimits,
+		},
+		PodGPUResources{
+			NvidiaGpuCountsRequests: a.NvidiaGpuCountsRequests + b.NvidiaGpuCountsRequests,
+			NvidiaGpuCountsLimits:   a.NvidiaGpuCountsLimits + b.NvidiaGpuCountsLimits,
+			AliyunGpuMemRequests:    a.AliyunGpuMemRequests + b.AliyunGpuMemRequests,
+			AliyunGpuMemLimits:      a.AliyunGpuMemLimits + b.AliyunGpuMemLimits,
+		},
+		addPodExtendedResources(a.ExtendedResources, b.ExtendedResources),
+		addPodStorageResources(a.EphemeralStorage, b.EphemeralStorage),
+		addPodStorageResources(a.HugePages, b.HugePages),
+	}
+}
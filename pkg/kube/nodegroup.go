@@ -0,0 +1,59 @@
+package kube
+
+import "sort"
+
+// NodeGroupNone is the group of the nodes without the label grouped by.
+const NodeGroupNone = "<none>"
+
+// NodeGroupResource is the allocated resources of the nodes sharing a label value.
+type NodeGroupResource struct {
+	// Value is the value of the label shared by the nodes.
+	Value string `json:"value"`
+
+	Nodes []NodeResource `json:"nodes"`
+
+	// Total is the sum of the allocated resources of Nodes.
+	Total ClusterResource `json:"total"`
+}
+
+// NodeGroupResourceList is the structured output of the node command grouped by a label.
+type NodeGroupResourceList struct {
+	// Label is the key of the label the nodes are grouped by.
+	Label string `json:"label"`
+
+	Items []NodeGroupResource `json:"items"`
+}
+
+// NodeGroupResources groups noderesources by the value of the label key, keeping the order of the nodes
+// within a group. Groups are sorted by value, the nodes without the label come last.
+func NodeGroupResources(noderesources []NodeResource, key string) []NodeGroupResource {
+	groups := make(map[string][]NodeResource)
+	for _, r := range noderesources {
+		value, ok := r.Labels[key]
+		if !ok {
+			value = NodeGroupNone
+		}
+		groups[value] = append(groups[value], r)
+	}
+
+	var values []string
+	for value := range groups {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i] == NodeGroupNone || values[j] == NodeGroupNone {
+			return values[j] == NodeGroupNone && values[i] != NodeGroupNone
+		}
+		return values[i] < values[j]
+	})
+
+	var resources []NodeGroupResource
+	for _, value := range values {
+		resources = append(resources, NodeGroupResource{
+			Value: value,
+			Nodes: groups[value],
+			Total: ClusterResources(groups[value]),
+		})
+	}
+	return resources
+}
@@ -0,0 +1,300 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics"
+)
+
+const (
+	// cAdvisor metrics scraped from the kubelets
+	prometheusCPUMetric    = "container_cpu_usage_seconds_total"
+	prometheusMemoryMetric = "container_memory_working_set_bytes"
+
+	// prometheusNodeLabel is the label of the cAdvisor metrics holding the node name, as set by kube-prometheus
+	prometheusNodeLabel = "node"
+
+	// prometheusRateInterval is the range of the cpu rate whose quantile is taken over the window
+	prometheusRateInterval = 5 * time.Minute
+)
+
+// prometheusUsage reads the usage from the cAdvisor metrics of a Prometheus server, averaged over a window
+// or at a quantile of the window, instead of the single sample of metrics-server.
+type prometheusUsage struct {
+	apiClient  kubernetes.Interface
+	httpClient *http.Client
+	address    string
+	window     time.Duration
+	quantile   float64
+}
+
+// prometheusResponse is the response of the instant query API of Prometheus
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]interface{}    `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// prometheusSample is a sample of an instant vector
+type prometheusSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// NewPrometheusUsage returns a UsageProvider querying the Prometheus server at address for the
+// container_cpu_usage_seconds_total and container_memory_working_set_bytes metrics. The usage is
+// the average over window, or the given quantile over window if quantile is greater than 0.
+// As the cAdvisor metrics do not have the labels of the pods, apiClient lists the pods matching
+// the label and field selectors, in pages like ListPods.
+func NewPrometheusUsage(apiClient kubernetes.Interface, address string, window time.Duration, quantile float64) (UsageProvider, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prometheus url %q: %v", address, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid prometheus url %q: the scheme must be http or https", address)
+	}
+	if window < time.Second {
+		return nil, fmt.Errorf("the window must be at least 1s, got %s", window)
+	}
+	if quantile < 0 || quantile > 1 {
+		return nil, fmt.Errorf("the quantile must be between 0 and 1, got %g", quantile)
+	}
+	return &prometheusUsage{
+		apiClient:  apiClient,
+		httpClient: http.DefaultClient,
+		address:    strings.TrimSuffix(address, "/"),
+		window:     window,
+		quantile:   quantile,
+	}, nil
+}
+
+// UsePrometheus reads the usage of nodes and pods from the Prometheus server at address instead of the
+// metrics API, see NewPrometheusUsage. The pods are listed with the core API client of k.
+func (k *KubeClient) UsePrometheus(address string, window time.Duration, quantile float64) error {
+	usage, err := NewPrometheusUsage(k.apiClient, address, window, quantile)
+	if err != nil {
+		return err
+	}
+	k.SetUsageProvider(usage)
+	return nil
+}
+
+//NodeMetrics returns the usage of the root cgroup of the nodes. The selector is not applied, the nodes
+//are matched by the callers joining them with the nodes of the core API.
+func (p *prometheusUsage) NodeMetrics(ctx context.Context, resourceName string, selector labels.Selector) (*metricsapi.NodeMetricsList, error) {
+	matchers := []string{`id="/"`}
+	if len(resourceName) > 0 {
+		matchers = append(matchers, prometheusNodeLabel+"="+strconv.Quote(resourceName))
+	}
+	usages, err := p.usages(ctx, matchers, []string{prometheusNodeLabel})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &metricsapi.NodeMetricsList{}
+	for _, key := range sortedKeys(usages) {
+		metrics.Items = append(metrics.Items, metricsapi.NodeMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: key[0]},
+			Timestamp:  metav1.Now(),
+			Window:     metav1.Duration{Duration: p.window},
+			Usage:      usages[key],
+		})
+	}
+	if len(resourceName) > 0 && len(metrics.Items) == 0 {
+		return nil, apierrors.NewNotFound(metricsapi.Resource("nodes"), resourceName)
+	}
+	return metrics, nil
+}
+
+//PodMetrics returns the usage of the containers of the pods, leaving out the pause containers
+func (p *prometheusUsage) PodMetrics(ctx context.Context, namespace, resourceName string, allNamespaces bool, labelSelector labels.Selector, fieldSelector fields.Selector) (*metricsapi.PodMetricsList, error) {
+	ns := metav1.NamespaceAll
+	if !allNamespaces {
+		ns = namespace
+	}
+	matchers := []string{`container!=""`, `container!="POD"`}
+	if len(ns) > 0 {
+		matchers = append(matchers, "namespace="+strconv.Quote(ns))
+	}
+	if len(resourceName) > 0 {
+		matchers = append(matchers, "pod="+strconv.Quote(resourceName))
+	}
+	usages, err := p.usages(ctx, matchers, []string{"namespace", "pod", "container"})
+	if err != nil {
+		return nil, err
+	}
+
+	var selected map[string]bool
+	if len(resourceName) == 0 && (!labelSelector.Empty() || !fieldSelector.Empty()) {
+		pods, err := listPods(ctx, p.apiClient, ns, metav1.ListOptions{
+			LabelSelector: labelSelector.String(),
+			FieldSelector: fieldSelector.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		selected = make(map[string]bool, len(pods))
+		for _, pod := range pods {
+			selected[pod.Namespace+"/"+pod.Name] = true
+		}
+	}
+
+	metrics := &metricsapi.PodMetricsList{}
+	for _, key := range sortedKeys(usages) {
+		if selected != nil && !selected[key[0]+"/"+key[1]] {
+			continue
+		}
+		container := metricsapi.ContainerMetrics{Name: key[2], Usage: usages[key]}
+		if n := len(metrics.Items); n > 0 && metrics.Items[n-1].Namespace == key[0] && metrics.Items[n-1].Name == key[1] {
+			metrics.Items[n-1].Containers = append(metrics.Items[n-1].Containers, container)
+			continue
+		}
+		metrics.Items = append(metrics.Items, metricsapi.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: key[1], Namespace: key[0]},
+			Timestamp:  metav1.Now(),
+			Window:     metav1.Duration{Duration: p.window},
+			Containers: []metricsapi.ContainerMetrics{container},
+		})
+	}
+	if len(resourceName) > 0 && len(metrics.Items) == 0 {
+		return nil, apierrors.NewNotFound(metricsapi.Resource("pods"), resourceName)
+	}
+	return metrics, nil
+}
+
+//usages queries the cpu and memory usage of the series matching matchers, summed up by the labels of by.
+//The usages are keyed by the values of these labels.
+func (p *prometheusUsage) usages(ctx context.Context, matchers []string, by []string) (map[[3]string]corev1.ResourceList, error) {
+	usages := map[[3]string]corev1.ResourceList{}
+	selector := "{" + strings.Join(matchers, ",") + "}"
+	for _, q := range []struct {
+		name    corev1.ResourceName
+		metric  string
+		counter bool
+	}{
+		{corev1.ResourceCPU, prometheusCPUMetric, true},
+		{corev1.ResourceMemory, prometheusMemoryMetric, false},
+	} {
+		samples, err := p.query(ctx, fmt.Sprintf("sum by (%s) (%s)", strings.Join(by, ", "), p.overWindow(q.metric+selector, q.counter)))
+		if err != nil {
+			return nil, err
+		}
+		for _, sample := range samples {
+			var key [3]string
+			for i, label := range by {
+				key[i] = sample.labels[label]
+			}
+			if _, ok := usages[key]; !ok {
+				usages[key] = corev1.ResourceList{}
+			}
+			if q.name == corev1.ResourceCPU {
+				usages[key][q.name] = *resource.NewMilliQuantity(int64(math.Round(sample.value*1000)), resource.DecimalSI)
+			} else {
+				usages[key][q.name] = *resource.NewQuantity(int64(math.Round(sample.value)), resource.BinarySI)
+			}
+		}
+	}
+	return usages, nil
+}
+
+//overWindow returns the expression of the average, or of the quantile, of series over the window.
+//The cpu counter is averaged by its rate over the window, its quantile is the one of its 5 minutes rate.
+func (p *prometheusUsage) overWindow(series string, counter bool) string {
+	window := promDuration(p.window)
+	switch {
+	case counter && p.quantile > 0:
+		interval := prometheusRateInterval
+		if p.window < interval {
+			interval = p.window
+		}
+		return fmt.Sprintf("quantile_over_time(%g, rate(%s[%s])[%s:])", p.quantile, series, promDuration(interval), window)
+	case counter:
+		return fmt.Sprintf("rate(%s[%s])", series, window)
+	case p.quantile > 0:
+		return fmt.Sprintf("quantile_over_time(%g, %s[%s])", p.quantile, series, window)
+	}
+	return fmt.Sprintf("avg_over_time(%s[%s])", series, window)
+}
+
+//query runs an instant query, leaving out the samples which are not a number
+func (p *prometheusUsage) query(ctx context.Context, query string) ([]prometheusSample, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/api/v1/query?"+url.Values{"query": {query}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("prometheus query failed with status %s: %v", resp.Status, err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("prometheus query returned a %s instead of a vector", result.Data.ResultType)
+	}
+
+	var samples []prometheusSample
+	for _, r := range result.Data.Result {
+		s, ok := r.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		samples = append(samples, prometheusSample{labels: r.Metric, value: value})
+	}
+	return samples, nil
+}
+
+//promDuration formats d in seconds, a duration every Prometheus version accepts
+func promDuration(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+}
+
+//sortedKeys returns the keys of usages in ascending order
+func sortedKeys(usages map[[3]string]corev1.ResourceList) [][3]string {
+	keys := make([][3]string, 0, len(usages))
+	for key := range usages {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for k := range keys[i] {
+			if keys[i][k] != keys[j][k] {
+				return keys[i][k] < keys[j][k]
+			}
+		}
+		return false
+	})
+	return keys
+}
@@ -0,0 +1,303 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics"
+)
+
+// fakePrometheus is a Prometheus server answering the cpu and the memory queries with canned bodies
+type fakePrometheus struct {
+	*httptest.Server
+	status      int
+	cpu, memory string
+	queries     []string
+}
+
+// newFakePrometheus starts a Prometheus server answering with status and the bodies of the cpu and memory queries
+func newFakePrometheus(t *testing.T, status int, cpu, memory string) *fakePrometheus {
+	p := &fakePrometheus{status: status, cpu: cpu, memory: memory}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			t.Errorf("got a request of %s, want /api/v1/query", r.URL.Path)
+		}
+		query := r.URL.Query().Get("query")
+		p.queries = append(p.queries, query)
+		w.WriteHeader(p.status)
+		if strings.Contains(query, prometheusCPUMetric) {
+			w.Write([]byte(p.cpu))
+		} else {
+			w.Write([]byte(p.memory))
+		}
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+// testSample is a sample of the instant vector of a fake Prometheus response
+type testSample struct {
+	labels map[string]string
+	value  string
+}
+
+// vector returns the body of a successful instant query returning samples
+func vector(samples ...testSample) string {
+	result := []interface{}{}
+	for _, s := range samples {
+		result = append(result, map[string]interface{}{"metric": s.labels, "value": []interface{}{1700000000.0, s.value}})
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"status": "success",
+		"data":   map[string]interface{}{"resultType": "vector", "result": result},
+	})
+	return string(data)
+}
+
+// nodeSample returns a sample of the node label
+func nodeSample(node, value string) testSample {
+	return testSample{map[string]string{"node": node}, value}
+}
+
+// containerSample returns a sample of the namespace, pod and container labels
+func containerSample(namespace, pod, container, value string) testSample {
+	return testSample{map[string]string{"namespace": namespace, "pod": pod, "container": container}, value}
+}
+
+// newTestPrometheusUsage returns the usage of the fake Prometheus server p
+func newTestPrometheusUsage(t *testing.T, p *fakePrometheus, window time.Duration, quantile float64) UsageProvider {
+	usage, err := NewPrometheusUsage(fake.NewSimpleClientset(), p.URL, window, quantile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return usage
+}
+
+func TestPrometheusQueries(t *testing.T) {
+	tests := []struct {
+		window    time.Duration
+		quantile  float64
+		cpu       string
+		memory    string
+		podCPU    string
+		podMemory string
+	}{
+		{
+			window:    10 * time.Minute,
+			cpu:       `sum by (node) (rate(container_cpu_usage_seconds_total{id="/"}[600s]))`,
+			memory:    `sum by (node) (avg_over_time(container_memory_working_set_bytes{id="/"}[600s]))`,
+			podCPU:    `sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total{container!="",container!="POD",namespace="default",pod="web"}[600s]))`,
+			podMemory: `sum by (namespace, pod, container) (avg_over_time(container_memory_working_set_bytes{container!="",container!="POD",namespace="default",pod="web"}[600s]))`,
+		},
+		{
+			window:    24 * time.Hour,
+			quantile:  0.95,
+			cpu:       `sum by (node) (quantile_over_time(0.95, rate(container_cpu_usage_seconds_total{id="/"}[300s])[86400s:]))`,
+			memory:    `sum by (node) (quantile_over_time(0.95, container_memory_working_set_bytes{id="/"}[86400s]))`,
+			podCPU:    `sum by (namespace, pod, container) (quantile_over_time(0.95, rate(container_cpu_usage_seconds_total{container!="",container!="POD",namespace="default",pod="web"}[300s])[86400s:]))`,
+			podMemory: `sum by (namespace, pod, container) (quantile_over_time(0.95, container_memory_working_set_bytes{container!="",container!="POD",namespace="default",pod="web"}[86400s]))`,
+		},
+		// the rate of the cpu quantile is not longer than the window
+		{
+			window:    2 * time.Minute,
+			quantile:  0.5,
+			cpu:       `sum by (node) (quantile_over_time(0.5, rate(container_cpu_usage_seconds_total{id="/"}[120s])[120s:]))`,
+			memory:    `sum by (node) (quantile_over_time(0.5, container_memory_working_set_bytes{id="/"}[120s]))`,
+			podCPU:    `sum by (namespace, pod, container) (quantile_over_time(0.5, rate(container_cpu_usage_seconds_total{container!="",container!="POD",namespace="default",pod="web"}[120s])[120s:]))`,
+			podMemory: `sum by (namespace, pod, container) (quantile_over_time(0.5, container_memory_working_set_bytes{container!="",container!="POD",namespace="default",pod="web"}[120s]))`,
+		},
+	}
+	for _, test := range tests {
+		p := newFakePrometheus(t, http.StatusOK,
+			vector(nodeSample("a", "1"), containerSample("default", "web", "app", "1")),
+			vector(nodeSample("a", "1"), containerSample("default", "web", "app", "1")))
+		usage := newTestPrometheusUsage(t, p, test.window, test.quantile)
+
+		if _, err := usage.NodeMetrics(context.Background(), "", labels.Everything()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := usage.PodMetrics(context.Background(), "default", "web", false, labels.Everything(), fields.Everything()); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{test.cpu, test.memory, test.podCPU, test.podMemory}
+		if len(p.queries) != len(want) {
+			t.Fatalf("window %s quantile %g: got queries %q, want %q", test.window, test.quantile, p.queries, want)
+		}
+		for i := range want {
+			if p.queries[i] != want[i] {
+				t.Errorf("window %s quantile %g: got query\n%s\nwant\n%s", test.window, test.quantile, p.queries[i], want[i])
+			}
+		}
+	}
+}
+
+func TestPrometheusNodeMetrics(t *testing.T) {
+	p := newFakePrometheus(t, http.StatusOK,
+		vector(nodeSample("b", "1.25"), nodeSample("a", "0.5"), nodeSample("c", "NaN")),
+		vector(nodeSample("a", "1048576"), nodeSample("b", "2147483648")))
+	usage := newTestPrometheusUsage(t, p, 5*time.Minute, 0)
+
+	metrics, err := usage.NodeMetrics(context.Background(), "", labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the sample which is not a number is left out, the nodes are sorted by name
+	want := []struct {
+		name   string
+		cpu    int64
+		memory int64
+	}{
+		{"a", 500, 1 << 20},
+		{"b", 1250, 2 << 30},
+	}
+	if len(metrics.Items) != len(want) {
+		t.Fatalf("got %d nodes, want %d", len(metrics.Items), len(want))
+	}
+	for i, w := range want {
+		m := metrics.Items[i]
+		cpu, memory := m.Usage[corev1.ResourceCPU], m.Usage[corev1.ResourceMemory]
+		if m.Name != w.name || cpu.MilliValue() != w.cpu || memory.Value() != w.memory {
+			t.Errorf("got node %s using %dm and %d bytes, want %s using %dm and %d bytes", m.Name, cpu.MilliValue(), memory.Value(), w.name, w.cpu, w.memory)
+		}
+		if m.Window.Duration != 5*time.Minute {
+			t.Errorf("got window %s, want 5m", m.Window.Duration)
+		}
+	}
+}
+
+func TestPrometheusPodMetrics(t *testing.T) {
+	p := newFakePrometheus(t, http.StatusOK,
+		vector(
+			containerSample("default", "web", "sidecar", "0.02"),
+			containerSample("default", "web", "app", "0.1"),
+			containerSample("default", "db", "db", "0.3"),
+		),
+		vector(
+			containerSample("default", "web", "sidecar", "16777216"),
+			containerSample("default", "web", "app", "134217728"),
+			containerSample("default", "db", "db", "268435456"),
+		))
+
+	web := fakePod("default", "web", "node-0", "100m", "128Mi")
+	web.Labels = map[string]string{"app": "web"}
+	db := fakePod("default", "db", "node-0", "100m", "128Mi")
+	db.Labels = map[string]string{"app": "db"}
+	apiClient := fake.NewSimpleClientset(web, db)
+
+	// the pods matching the label selector are listed with the core API client of the KubeClient
+	k := &KubeClient{apiClient: apiClient}
+	if err := k.UsePrometheus(p.URL, 5*time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics, err := k.GetPodMetricsFromMetricsAPI(context.Background(), "default", "", false, labels.Everything(), fields.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := podMetricsNames(metrics); got != "default/db[db] default/web[app,sidecar]" {
+		t.Errorf("got pods %s, want default/db[db] default/web[app,sidecar]", got)
+	}
+	app := metrics.Items[1].Containers[0].Usage
+	if cpu, memory := app[corev1.ResourceCPU], app[corev1.ResourceMemory]; cpu.MilliValue() != 100 || memory.Value() != 128<<20 {
+		t.Errorf("got app using %dm and %d bytes, want 100m and %d bytes", cpu.MilliValue(), memory.Value(), 128<<20)
+	}
+
+	apiClient.ClearActions()
+	metrics, err = k.GetPodMetricsFromMetricsAPI(context.Background(), "default", "", false, labels.SelectorFromSet(labels.Set{"app": "web"}), fields.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := podMetricsNames(metrics); got != "default/web[app,sidecar]" {
+		t.Errorf("got pods %s with -l app=web, want default/web[app,sidecar]", got)
+	}
+	if actions := apiClient.Actions(); len(actions) != 1 || actions[0].GetVerb() != "list" || actions[0].GetResource().Resource != "pods" {
+		t.Errorf("got API calls %v, want a single list of the pods", actions)
+	}
+}
+
+// podMetricsNames describes the pods of metrics and their containers, e.g. default/web[app,sidecar]
+func podMetricsNames(metrics *metricsapi.PodMetricsList) string {
+	var names []string
+	for _, m := range metrics.Items {
+		var containers []string
+		for _, c := range m.Containers {
+			containers = append(containers, c.Name)
+		}
+		names = append(names, m.Namespace+"/"+m.Name+"["+strings.Join(containers, ",")+"]")
+	}
+	return strings.Join(names, " ")
+}
+
+func TestPrometheusErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "error status",
+			status:  http.StatusBadRequest,
+			body:    `{"status":"error","errorType":"bad_data","error":"parse error at char 5"}`,
+			wantErr: "prometheus query failed: parse error at char 5",
+		},
+		{
+			name:    "not json",
+			status:  http.StatusBadGateway,
+			body:    "bad gateway",
+			wantErr: "prometheus query failed with status 502 Bad Gateway",
+		},
+		{
+			name:    "matrix",
+			status:  http.StatusOK,
+			body:    `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			wantErr: "prometheus query returned a matrix instead of a vector",
+		},
+	}
+	for _, test := range tests {
+		p := newFakePrometheus(t, test.status, test.body, test.body)
+		usage := newTestPrometheusUsage(t, p, 5*time.Minute, 0)
+
+		_, err := usage.NodeMetrics(context.Background(), "", labels.Everything())
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+		}
+		_, err = usage.PodMetrics(context.Background(), "default", "", false, labels.Everything(), fields.Everything())
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: got error %v of the pods, want %q", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestPrometheusEmpty(t *testing.T) {
+	p := newFakePrometheus(t, http.StatusOK, vector(), vector())
+	usage := newTestPrometheusUsage(t, p, 5*time.Minute, 0)
+	ctx := context.Background()
+
+	nodes, err := usage.NodeMetrics(ctx, "", labels.Everything())
+	if err != nil || len(nodes.Items) != 0 {
+		t.Errorf("got %v and error %v, want no nodes", nodes, err)
+	}
+	pods, err := usage.PodMetrics(ctx, "default", "", false, labels.Everything(), fields.Everything())
+	if err != nil || len(pods.Items) != 0 {
+		t.Errorf("got %v and error %v, want no pods", pods, err)
+	}
+
+	// a named node or pod without usage is not found, like with the metrics API
+	if _, err := usage.NodeMetrics(ctx, "a", labels.Everything()); !apierrors.IsNotFound(err) {
+		t.Errorf("got error %v for node a, want not found", err)
+	}
+	if _, err := usage.PodMetrics(ctx, "default", "web", false, labels.Everything(), fields.Everything()); !apierrors.IsNotFound(err) {
+		t.Errorf("got error %v for pod web, want not found", err)
+	}
+}
@@ -0,0 +1,271 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Status of a recommendation.
+const (
+	StatusOK               = "ok"
+	StatusOverProvisioned  = "over-provisioned"
+	StatusUnderProvisioned = "under-provisioned"
+)
+
+const (
+	// minCPURequests is the smallest cpu request recommended, in millicores.
+	minCPURequests = 10
+	// minMemoryRequests is the smallest memory request recommended, in bytes.
+	minMemoryRequests = 16 * 1024 * 1024
+	// overProvisionedFraction is the percentage of the request below which the usage is far below the request.
+	overProvisionedFraction = 50
+)
+
+// CPURecommendation is the current and recommended cpu of a container.
+type CPURecommendation struct {
+	Usage    *CpuResource `json:"usage"`
+	Requests *CpuResource `json:"requests"`
+	Limits   *CpuResource `json:"limits"`
+
+	RecommendedRequests *CpuResource `json:"recommendedRequests"`
+	RecommendedLimits   *CpuResource `json:"recommendedLimits"`
+
+	Status string `json:"status"`
+	// Reason explains the status, empty when the status is ok.
+	Reason string `json:"reason,omitempty"`
+}
+
+// MemoryRecommendation is the current and recommended memory of a container.
+type MemoryRecommendation struct {
+	Usage    *MemoryResource `json:"usage"`
+	Requests *MemoryResource `json:"requests"`
+	Limits   *MemoryResource `json:"limits"`
+
+	RecommendedRequests *MemoryResource `json:"recommendedRequests"`
+	RecommendedLimits   *MemoryResource `json:"recommendedLimits"`
+
+	Status string `json:"status"`
+	// Reason explains the status, empty when the status is ok.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ContainerRecommendation is the recommended requests and limits of a container of a pod.
+type ContainerRecommendation struct {
+	Namespace string `json:"namespace"`
+
+	// WorkloadKind and WorkloadName are the top level workload owning the pod, kind Pod for bare pods.
+	WorkloadKind string `json:"workloadKind"`
+	WorkloadName string `json:"workloadName"`
+
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+
+	// Sidecar is true for a restartable init container, which is patched in the init containers.
+	Sidecar bool `json:"sidecar,omitempty"`
+
+	CPU    CPURecommendation    `json:"cpu"`
+	Memory MemoryRecommendation `json:"memory"`
+}
+
+// ContainerRecommendationList is the structured output of the recommend command.
+type ContainerRecommendationList struct {
+	Items []ContainerRecommendation `json:"items"`
+}
+
+// WorkloadRecommendation is the recommended requests and limits of the containers of a workload,
+// the highest recommendation of the pods of the workload for every container.
+type WorkloadRecommendation struct {
+	Namespace string
+	Kind      string
+	Name      string
+
+	// Containers are ordered by name.
+	Containers []ContainerRecommendation
+}
+
+// RecommendOptions configures the recommendations.
+type RecommendOptions struct {
+	// Headroom is the percentage added to the usage to get the recommended request.
+	Headroom float64
+	// LimitFactor is the ratio of the recommended limit to the recommended request,
+	// 0 keeps the current limits.
+	LimitFactor float64
+	// Thresholds are the usage percentages of the limit a container is under-provisioned at.
+	Thresholds Thresholds
+}
+
+// GetRecommendations compares the usage of the containers of podresources to their requests and limits
+// and recommends new ones. Init containers are skipped as they are not running, except the sidecars, and so
+// are the containers of the pods without usage, e.g. pending ones.
+func (k *KubeClient) GetRecommendations(ctx context.Context, podresources []PodResource, namespace string, allNamespaces bool, opts RecommendOptions) ([]ContainerRecommendation, error) {
+	owners, err := k.getWorkloadOwners(ctx, namespace, allNamespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var recommendations []ContainerRecommendation
+	for _, podresource := range podresources {
+		key := podWorkload(podresource, owners)
+		for _, c := range podresource.Containers {
+			if (c.Init && !c.Sidecar) || c.CPUUsages == nil || c.MemoryUsages == nil {
+				continue
+			}
+			recommendations = append(recommendations, ContainerRecommendation{
+				Namespace:    podresource.Namespace,
+				WorkloadKind: key.kind,
+				WorkloadName: key.name,
+				Pod:          podresource.Name,
+				Container:    c.Name,
+				Sidecar:      c.Sidecar,
+				CPU:          recommendCPU(c.PodCPUResources, opts),
+				Memory:       recommendMemory(c.PodMemoryResources, opts),
+			})
+		}
+	}
+	return recommendations, nil
+}
+
+// WorkloadRecommendations groups recommendations by workload, keeping for every container the highest
+// recommendation of the pods of the workload. Workloads are ordered by namespace, kind and name.
+func WorkloadRecommendations(recommendations []ContainerRecommendation) []WorkloadRecommendation {
+	index := make(map[workloadKey]int)
+	var workloads []WorkloadRecommendation
+	for _, r := range recommendations {
+		key := workloadKey{r.Namespace, r.WorkloadKind, r.WorkloadName}
+		i, ok := index[key]
+		if !ok {
+			i = len(workloads)
+			index[key] = i
+			workloads = append(workloads, WorkloadRecommendation{Namespace: key.namespace, Kind: key.kind, Name: key.name})
+		}
+		containers := workloads[i].Containers
+		j := 0
+		for j < len(containers) && containers[j].Container != r.Container {
+			j++
+		}
+		if j == len(containers) {
+			workloads[i].Containers = append(containers, r)
+			continue
+		}
+		if r.CPU.RecommendedRequests.MilliValue() > containers[j].CPU.RecommendedRequests.MilliValue() {
+			containers[j].CPU = r.CPU
+		}
+		if r.Memory.RecommendedRequests.Value() > containers[j].Memory.RecommendedRequests.Value() {
+			containers[j].Memory = r.Memory
+		}
+	}
+
+	for _, w := range workloads {
+		sort.Slice(w.Containers, func(i, j int) bool { return w.Containers[i].Container < w.Containers[j].Container })
+	}
+	sort.SliceStable(workloads, func(i, j int) bool {
+		a, b := workloads[i], workloads[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return workloads
+}
+
+// OK reports whether neither the cpu nor the memory of the container need to change.
+func (r ContainerRecommendation) OK() bool {
+	return r.CPU.Status == StatusOK && r.Memory.Status == StatusOK
+}
+
+//recommendCPU
+func recommendCPU(r PodCPUResources, opts RecommendOptions) CPURecommendation {
+	usage, requests, limits := r.CPUUsages.MilliValue(), r.CPURequests.MilliValue(), r.CPULimits.MilliValue()
+	recommendedRequests := withHeadroom(usage, opts.Headroom)
+	if recommendedRequests < minCPURequests {
+		recommendedRequests = minCPURequests
+	}
+	status, reason := provisioning(usage, requests, limits, ThresholdCPUUsage, opts.Thresholds)
+	return CPURecommendation{
+		Usage:               r.CPUUsages,
+		Requests:            r.CPURequests,
+		Limits:              r.CPULimits,
+		RecommendedRequests: NewCpuResource(recommendedRequests),
+		RecommendedLimits:   NewCpuResource(recommendLimits(recommendedRequests, limits, opts.LimitFactor)),
+		Status:              status,
+		Reason:              reason,
+	}
+}
+
+//recommendMemory
+func recommendMemory(r PodMemoryResources, opts RecommendOptions) MemoryRecommendation {
+	usage, requests, limits := r.MemoryUsages.Value(), r.MemoryRequests.Value(), r.MemoryLimits.Value()
+	recommendedRequests := roundUpMebibytes(withHeadroom(usage, opts.Headroom))
+	if recommendedRequests < minMemoryRequests {
+		recommendedRequests = minMemoryRequests
+	}
+	status, reason := provisioning(usage, requests, limits, ThresholdMemUsage, opts.Thresholds)
+	return MemoryRecommendation{
+		Usage:               r.MemoryUsages,
+		Requests:            r.MemoryRequests,
+		Limits:              r.MemoryLimits,
+		RecommendedRequests: NewMemoryResource(recommendedRequests),
+		RecommendedLimits:   NewMemoryResource(roundUpMebibytes(recommendLimits(recommendedRequests, limits, opts.LimitFactor))),
+		Status:              status,
+		Reason:              reason,
+	}
+}
+
+//provisioning returns the status of a resource and the reasons of it. The request is judged first: the
+//resource is under-provisioned when it has no request or when its usage is above its request,
+//over-provisioned when its usage is far below its request. The limit is judged then: the resource is
+//under-provisioned when the usage percentage of its limit reaches the warning threshold of column, and a
+//missing limit is added to the reasons, making a resource whose request is ok under-provisioned.
+func provisioning(usage, requests, limits int64, column string, t Thresholds) (string, string) {
+	status := StatusOK
+	var reasons []string
+	switch {
+	case requests == 0:
+		status, reasons = StatusUnderProvisioned, []string{"no request"}
+	case usage > requests:
+		status, reasons = StatusUnderProvisioned, []string{fmt.Sprintf("usage at %v%% of request", calcPercentage(usage, requests))}
+	case calcPercentage(usage, requests) < overProvisionedFraction:
+		status, reasons = StatusOverProvisioned, []string{fmt.Sprintf("usage at %v%% of request", calcPercentage(usage, requests))}
+	}
+
+	switch {
+	case limits == 0:
+		if status == StatusOK {
+			status = StatusUnderProvisioned
+		}
+		reasons = append(reasons, "no limit")
+	case t.Level(column, calcPercentage(usage, limits)) != LevelOK:
+		status = StatusUnderProvisioned
+		reasons = append(reasons, fmt.Sprintf("usage at %v%% of limit", calcPercentage(usage, limits)))
+	}
+	return status, strings.Join(reasons, ", ")
+}
+
+//withHeadroom adds headroom percent to value, rounding up
+func withHeadroom(value int64, headroom float64) int64 {
+	return int64(math.Ceil(float64(value) * (1 + headroom/100)))
+}
+
+//recommendLimits returns the recommended requests times factor, or the current limits when factor is 0.
+//The limits are never below the recommended requests.
+func recommendLimits(recommendedRequests, limits int64, factor float64) int64 {
+	if factor > 0 {
+		limits = int64(math.Ceil(float64(recommendedRequests) * factor))
+	}
+	if limits > 0 && limits < recommendedRequests {
+		return recommendedRequests
+	}
+	return limits
+}
+
+//roundUpMebibytes rounds bytes up to a whole number of mebibytes
+func roundUpMebibytes(bytes int64) int64 {
+	const mebibyte = 1024 * 1024
+	return (bytes + mebibyte - 1) / mebibyte * mebibyte
+}
@@ -0,0 +1,147 @@
+package kube
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// cpuResources returns the cpu of a container using, requesting and limited to millicores, 0 for none
+func cpuResources(usage, requests, limits int64) PodCPUResources {
+	return PodCPUResources{CPUUsages: NewCpuResource(usage), CPURequests: NewCpuResource(requests), CPULimits: NewCpuResource(limits)}
+}
+
+// memoryResources returns the memory of a container using, requesting and limited to bytes, 0 for none
+func memoryResources(usage, requests, limits int64) PodMemoryResources {
+	return PodMemoryResources{MemoryUsages: NewMemoryResource(usage), MemoryRequests: NewMemoryResource(requests), MemoryLimits: NewMemoryResource(limits)}
+}
+
+var testRecommendOptions = RecommendOptions{Headroom: 20, LimitFactor: 2, Thresholds: DefaultThresholds()}
+
+func TestRecommendCPU(t *testing.T) {
+	tests := []struct {
+		name       string
+		resources  PodCPUResources
+		status     string
+		reason     string
+		requests   int64
+		limits     int64
+		keepLimits bool
+	}{
+		{name: "ok", resources: cpuResources(400, 500, 1000), status: StatusOK, requests: 480, limits: 960},
+		{name: "over", resources: cpuResources(100, 500, 1000), status: StatusOverProvisioned, reason: "usage at 20% of request", requests: 120, limits: 240},
+		{name: "above request", resources: cpuResources(600, 500, 1000), status: StatusUnderProvisioned, reason: "usage at 120% of request", requests: 720, limits: 1440},
+		{name: "no request", resources: cpuResources(100, 0, 1000), status: StatusUnderProvisioned, reason: "no request", requests: 120, limits: 240},
+		{name: "at limit", resources: cpuResources(950, 1000, 1000), status: StatusUnderProvisioned, reason: "usage at 95% of limit", requests: 1140, limits: 2280},
+		// a missing limit does not hide an over-provisioned request
+		{name: "over without limit", resources: cpuResources(25, 500, 0), status: StatusOverProvisioned, reason: "usage at 5% of request, no limit", requests: 30, limits: 60},
+		{name: "ok without limit", resources: cpuResources(400, 500, 0), status: StatusUnderProvisioned, reason: "no limit", requests: 480, limits: 960},
+		{name: "above request at limit", resources: cpuResources(950, 500, 1000), status: StatusUnderProvisioned, reason: "usage at 190% of request, usage at 95% of limit", requests: 1140, limits: 2280},
+		// the request is never below the floor
+		{name: "floor", resources: cpuResources(1, 500, 1000), status: StatusOverProvisioned, reason: "usage at 0.2% of request", requests: minCPURequests, limits: 2 * minCPURequests},
+		// a limit factor of 0 keeps the limit, but not below the recommended request
+		{name: "keep limits", resources: cpuResources(400, 500, 1000), status: StatusOK, requests: 480, limits: 1000, keepLimits: true},
+		{name: "raise limits", resources: cpuResources(910, 1000, 1000), status: StatusUnderProvisioned, reason: "usage at 91% of limit", requests: 1092, limits: 1092, keepLimits: true},
+		{name: "keep no limit", resources: cpuResources(400, 500, 0), status: StatusUnderProvisioned, reason: "no limit", requests: 480, limits: 0, keepLimits: true},
+	}
+	for _, test := range tests {
+		opts := testRecommendOptions
+		if test.keepLimits {
+			opts.LimitFactor = 0
+		}
+		r := recommendCPU(test.resources, opts)
+		if r.Status != test.status || r.Reason != test.reason {
+			t.Errorf("%s: got %s (%s), want %s (%s)", test.name, r.Status, r.Reason, test.status, test.reason)
+		}
+		if r.RecommendedRequests.MilliValue() != test.requests || r.RecommendedLimits.MilliValue() != test.limits {
+			t.Errorf("%s: got requests %s and limits %s, want %dm and %dm", test.name, r.RecommendedRequests, r.RecommendedLimits, test.requests, test.limits)
+		}
+	}
+}
+
+func TestRecommendMemory(t *testing.T) {
+	const mi = 1024 * 1024
+	tests := []struct {
+		name      string
+		resources PodMemoryResources
+		status    string
+		reason    string
+		requests  int64
+		limits    int64
+	}{
+		{name: "ok", resources: memoryResources(400*mi, 500*mi, 1000*mi), status: StatusOK, requests: 480 * mi, limits: 960 * mi},
+		// the recommendations are rounded up to whole mebibytes
+		{name: "round up", resources: memoryResources(400*mi+1, 500*mi, 1000*mi), status: StatusOK, requests: 481 * mi, limits: 962 * mi},
+		{name: "over without limit", resources: memoryResources(25*mi, 500*mi, 0), status: StatusOverProvisioned, reason: "usage at 5% of request, no limit", requests: 30 * mi, limits: 60 * mi},
+		{name: "at limit", resources: memoryResources(960*mi, 1000*mi, 1000*mi), status: StatusUnderProvisioned, reason: "usage at 96% of limit", requests: 1152 * mi, limits: 2304 * mi},
+		{name: "floor", resources: memoryResources(1*mi, 10*mi, 20*mi), status: StatusOverProvisioned, reason: "usage at 10% of request", requests: minMemoryRequests, limits: 2 * minMemoryRequests},
+	}
+	for _, test := range tests {
+		r := recommendMemory(test.resources, testRecommendOptions)
+		if r.Status != test.status || r.Reason != test.reason {
+			t.Errorf("%s: got %s (%s), want %s (%s)", test.name, r.Status, r.Reason, test.status, test.reason)
+		}
+		if r.RecommendedRequests.Value() != test.requests || r.RecommendedLimits.Value() != test.limits {
+			t.Errorf("%s: got requests %d and limits %d, want %d and %d", test.name, r.RecommendedRequests.Value(), r.RecommendedLimits.Value(), test.requests, test.limits)
+		}
+	}
+}
+
+// testRecommendation returns the recommendation of a container of a pod of a deployment recommending cpu and memory
+func testRecommendation(workload, pod, container string, cpu, memory int64) ContainerRecommendation {
+	return ContainerRecommendation{
+		Namespace:    "default",
+		WorkloadKind: "Deployment",
+		WorkloadName: workload,
+		Pod:          pod,
+		Container:    container,
+		CPU:          CPURecommendation{RecommendedRequests: NewCpuResource(cpu)},
+		Memory:       MemoryRecommendation{RecommendedRequests: NewMemoryResource(memory)},
+	}
+}
+
+func TestWorkloadRecommendations(t *testing.T) {
+	workloads := WorkloadRecommendations([]ContainerRecommendation{
+		testRecommendation("web", "web-1", "sidecar", 10, 64<<20),
+		testRecommendation("web", "web-1", "app", 100, 256<<20),
+		testRecommendation("db", "db-0", "db", 500, 1<<30),
+		testRecommendation("web", "web-2", "app", 300, 128<<20),
+	})
+	if len(workloads) != 2 || workloads[0].Name != "db" || workloads[1].Name != "web" {
+		t.Fatalf("got %+v, want the workloads db and web", workloads)
+	}
+	web := workloads[1].Containers
+	if len(web) != 2 || web[0].Container != "app" || web[1].Container != "sidecar" {
+		t.Fatalf("got the containers %+v of web, want app and sidecar", web)
+	}
+	// the highest cpu and the highest memory may come from different pods
+	if web[0].CPU.RecommendedRequests.MilliValue() != 300 || web[0].Memory.RecommendedRequests.Value() != 256<<20 {
+		t.Errorf("got app recommended %s and %s, want 300m and 256Mi", web[0].CPU.RecommendedRequests, web[0].Memory.RecommendedRequests)
+	}
+}
+
+func TestContainerSidecars(t *testing.T) {
+	pod := fakePod("default", "web", "node-0", "100m", "128Mi")
+	pod.Spec.InitContainers = []corev1.Container{{Name: "migrate"}, {Name: "proxy"}}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+		{Name: "proxy", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+	}
+
+	containers := getContainerAllocatedResources(pod, nil)
+	if len(containers) != 3 {
+		t.Fatalf("got %d containers, want 3", len(containers))
+	}
+	if c := containers[0]; c.Name != "migrate" || !c.Init || c.Sidecar {
+		t.Errorf("got %+v, want migrate an init container which is not a sidecar", c)
+	}
+	if c := containers[1]; c.Name != "proxy" || !c.Init || !c.Sidecar {
+		t.Errorf("got %+v, want proxy a sidecar", c)
+	}
+
+	// the init containers of a pending pod are running before the other containers
+	pod.Status.Phase = corev1.PodPending
+	if c := getContainerAllocatedResources(pod, nil)[1]; c.Sidecar {
+		t.Errorf("got proxy a sidecar in a pending pod, want an init container")
+	}
+}
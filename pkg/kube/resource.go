@@ -3,6 +3,7 @@ package kube
 import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kubectl/pkg/metricsutil"
 	metricsapi "k8s.io/metrics/pkg/apis/metrics"
 )
@@ -10,85 +11,85 @@ import (
 // CPUResources describes node allocated resources.
 type CPUResources struct {
 	// CPUUsages is number of allocated milicores.
-	CPUUsages *CpuResource
+	CPUUsages *CpuResource `json:"usage"`
 
 	// CPURequests is number of allocated milicores.
-	CPURequests *CpuResource
+	CPURequests *CpuResource `json:"requests"`
 
 	// CPURequestsFraction is a fraction of CPU, that is allocated.
-	CPURequestsFraction float64 `json:"cpuRequestsFraction"`
+	CPURequestsFraction float64 `json:"requestsFraction"`
 
 	// CPULimits is defined CPU limit.
-	CPULimits *CpuResource
+	CPULimits *CpuResource `json:"limits"`
 
 	// CPULimitsFraction is a fraction of defined CPU limit, can be over 100%, i.e.
 	// overcommitted.
-	CPULimitsFraction float64 `json:"cpuLimitsFraction"`
+	CPULimitsFraction float64 `json:"limitsFraction"`
 
 	// CPUCapacity is specified node CPU capacity in milicores.
-	CPUCapacity *CpuResource
+	CPUCapacity *CpuResource `json:"capacity"`
 }
 
 // MemoryResources describes node allocated resources.
 type MemoryResources struct {
 	// MemoryUsages is a fraction of memory, that is allocated.
-	MemoryUsages *MemoryResource
+	MemoryUsages *MemoryResource `json:"usage"`
 
 	// MemoryRequests is a fraction of memory, that is allocated.
-	MemoryRequests *MemoryResource
+	MemoryRequests *MemoryResource `json:"requests"`
 
 	// MemoryRequestsFraction is a fraction of memory, that is allocated.
-	MemoryRequestsFraction float64 `json:"memoryRequestsFraction"`
+	MemoryRequestsFraction float64 `json:"requestsFraction"`
 
 	// MemoryLimits is defined memory limit.
-	MemoryLimits *MemoryResource
+	MemoryLimits *MemoryResource `json:"limits"`
 
 	// MemoryLimitsFraction is a fraction of defined memory limit, can be over 100%, i.e.
 	// overcommitted.
-	MemoryLimitsFraction float64 `json:"memoryLimitsFraction"`
+	MemoryLimitsFraction float64 `json:"limitsFraction"`
 
 	// MemoryCapacity is specified node memory capacity in bytes.
-	MemoryCapacity *MemoryResource
+	MemoryCapacity *MemoryResource `json:"capacity"`
 }
 
 // PodResources describes node allocated resources.
 type PodResources struct {
 	// AllocatedPods in number of currently allocated pods on the node.
-	AllocatedPods int `json:"allocatedPods"`
+	AllocatedPods int `json:"allocated"`
 
 	// PodCapacity is maximum number of pods, that can be allocated on the node.
-	PodCapacity int64 `json:"podCapacity"`
+	PodCapacity int64 `json:"capacity"`
 
 	// PodFraction is a fraction of pods, that can be allocated on given node.
-	PodFraction float64 `json:"podFraction"`
+	PodFraction float64 `json:"fraction"`
 }
 
 // GPUResources describes node allocated resources.
 type GPUResources struct {
 	// NvidiaGpuCountsRequests is a fraction of NvidiaGpuCountsRequests, that is allocated.
-	NvidiaGpuCountsRequests int64
+	NvidiaGpuCountsRequests int64 `json:"nvidiaGpuCountsRequests"`
 
 	// NvidiaGpuCountsRequestsFraction is a fraction of NvidiaGpuCountsRequests, that is allocated.
-	NvidiaGpuCountsRequestsFraction float64 `json:"NvidiaGpuCountsRequestsFraction"`
+	NvidiaGpuCountsRequestsFraction float64 `json:"nvidiaGpuCountsRequestsFraction"`
 
 	// NvidiaGpuCountsLimits is defined NvidiaGpuCounts limit.
-	NvidiaGpuCountsLimits int64
+	NvidiaGpuCountsLimits int64 `json:"nvidiaGpuCountsLimits"`
 
 	// NvidiaGpuCountsLimitsFraction is a fraction of defined NvidiaGpuCounts limit, can be over 100%, i.e.
 	// overcommitted.
-	NvidiaGpuCountsLimitsFraction float64 `json:"NvidiaGpuCountsLimitsFraction"`
+	NvidiaGpuCountsLimitsFraction float64 `json:"nvidiaGpuCountsLimitsFraction"`
 
 	// NvidiaGpuCountsCapacity is maximum number of pods, that can be allocated on the node.
 	NvidiaGpuCountsCapacity int64 `json:"nvidiaGpuCountsCapacity"`
 
 	// AliyunGpuMemRequests is a fraction of AliyunGpuMemRequests, that is allocated.
-	AliyunGpuMemRequests int64
+	AliyunGpuMemRequests int64 `json:"aliyunGpuMemRequests"`
 
 	// AliyunGpuMemRequestsFraction is a fraction of AliyunGpuMemRequests, that is allocated.
-	AliyunGpuMemRequestsFraction float64 `json:"aliyunGpuMemsRequestsFraction"`
+	AliyunGpuMemRequestsFraction float64 `json:"aliyunGpuMemRequestsFraction"`
 
 	// AliyunGpuMemLimits is defined AliyunGpuMem limit.
-	AliyunGpuMemLimits int64
+	AliyunGpuMemLimits int64 `json:"aliyunGpuMemLimits"`
 
 	// NvidiaGpuCountsLimitsFraction is a fraction of defined NvidiaGpuCounts limit, can be over 100%, i.e.
 	// overcommitted.
@@ -100,49 +101,141 @@ type GPUResources struct {
 
 // NodeAllocatedResources describes node allocated resources.
 type NodeAllocatedResources struct {
-	CPUResources
-	MemoryResources
-	GPUResources
-	PodResources
+	CPUResources    `json:"cpu"`
+	MemoryResources `json:"memory"`
+	GPUResources    `json:"gpu"`
+	PodResources    `json:"pods"`
+
+	// ExtendedResources are the extended resources of the allocatable capacity of the node.
+	ExtendedResources ExtendedResources `json:"extended,omitempty"`
+
+	// EphemeralStorage is the allocation of the ephemeral-storage of the node.
+	EphemeralStorage StorageResources `json:"ephemeralStorage"`
+
+	// HugePages is the allocation of the hugepages of all page sizes of the node.
+	HugePages StorageResources `json:"hugepages"`
 }
 
-// PodAllocatedResources describes node allocated resources.
-type PodAllocatedResources struct {
+// PodCPUResources describes pod allocated cpu.
+type PodCPUResources struct {
 	// CPUUsages is number of allocated milicores.
-	CPUUsages *CpuResource
+	CPUUsages *CpuResource `json:"usage"`
 
 	// CPURequestsFraction is a fraction of CPU, that is allocated.
-	CPUUsagesFraction float64 `json:"cpuUsagesFraction"`
+	CPUUsagesFraction float64 `json:"usageFraction"`
 
 	// CPURequests is number of allocated milicores.
-	CPURequests *CpuResource
+	CPURequests *CpuResource `json:"requests"`
 
 	// CPULimits is defined CPU limit.
-	CPULimits *CpuResource
+	CPULimits *CpuResource `json:"limits"`
+}
 
+// PodMemoryResources describes pod allocated memory.
+type PodMemoryResources struct {
 	// MemoryUsages is a fraction of memory, that is allocated.
-	MemoryUsages *MemoryResource
+	MemoryUsages *MemoryResource `json:"usage"`
 
 	// MemoryRequestsFraction is a fraction of memory, that is allocated.
-	MemoryUsagesFraction float64 `json:"memoryUsagesFraction"`
+	MemoryUsagesFraction float64 `json:"usageFraction"`
 
 	// MemoryRequests is a fraction of memory, that is allocated.
-	MemoryRequests *MemoryResource
+	MemoryRequests *MemoryResource `json:"requests"`
 
 	// MemoryLimits is defined memory limit.
-	MemoryLimits *MemoryResource
+	MemoryLimits *MemoryResource `json:"limits"`
+}
 
+// PodGPUResources describes pod allocated gpu.
+type PodGPUResources struct {
 	// NvidiaGpuCountsRequests is a fraction of NvidiaGpuCounts, that is allocated.
-	NvidiaGpuCountsRequests int64
+	NvidiaGpuCountsRequests int64 `json:"nvidiaGpuCountsRequests"`
 
 	// NvidiaGpuCountsLimits is defined NvidiaGpuCounts limit.
-	NvidiaGpuCountsLimits int64
+	NvidiaGpuCountsLimits int64 `json:"nvidiaGpuCountsLimits"`
 
 	// AliyunGpuMemRequests is a fraction of AliyunGpuMem, that is allocated.
-	AliyunGpuMemRequests int64
+	AliyunGpuMemRequests int64 `json:"aliyunGpuMemRequests"`
 
 	// AliyunGpuMemLimits is defined AliyunGpuMem limit.
-	AliyunGpuMemLimits int64
+	AliyunGpuMemLimits int64 `json:"aliyunGpuMemLimits"`
+}
+
+// PodAllocatedResources describes node allocated resources.
+type PodAllocatedResources struct {
+	PodCPUResources    `json:"cpu"`
+	PodMemoryResources `json:"memory"`
+	PodGPUResources    `json:"gpu"`
+
+	// ExtendedResources are the extended resources requested or limited.
+	ExtendedResources PodExtendedResources `json:"extended,omitempty"`
+
+	// EphemeralStorage is the allocation of ephemeral-storage.
+	EphemeralStorage PodStorageResources `json:"ephemeralStorage"`
+
+	// HugePages is the allocation of the hugepages of all page sizes.
+	HugePages PodStorageResources `json:"hugepages"`
+}
+
+// NodeResource is the allocated resources of a single node.
+type NodeResource struct {
+	Name string `json:"name"`
+
+	// Labels are the labels of the node, used to group nodes.
+	Labels map[string]string `json:"-"`
+
+	NodeAllocatedResources
+
+	// UsageStats are the statistics of the usage samples with --sample-for, nil otherwise.
+	UsageStats *UsageStats `json:"usageStats,omitempty"`
+}
+
+// PodResource is the allocated resources of a single pod.
+type PodResource struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// NodeName is the name of the node the pod is scheduled on.
+	NodeName string `json:"node"`
+
+	// Phase is the phase of the pod, e.g. Pending or Running.
+	Phase v1.PodPhase `json:"phase"`
+
+	// Controller is the owner reference of the controller of the pod, nil for bare pods. It resolves the
+	// workload of the pod without listing the pods again.
+	Controller *metav1.OwnerReference `json:"-"`
+
+	PodAllocatedResources
+
+	// UsageStats are the statistics of the usage samples with --sample-for, nil otherwise.
+	UsageStats *UsageStats `json:"usageStats,omitempty"`
+
+	// Containers is the breakdown of the pod by container, init containers first.
+	Containers []ContainerResource `json:"containers"`
+}
+
+// ContainerResource is the allocated resources of a single container of a pod.
+type ContainerResource struct {
+	Name string `json:"name"`
+
+	// Init is true for init containers, including sidecars declared as init containers.
+	Init bool `json:"init"`
+
+	// Sidecar is true for the init containers still running in a running pod, i.e. restartable init
+	// containers which run for the whole life of the pod.
+	Sidecar bool `json:"sidecar,omitempty"`
+
+	PodAllocatedResources
+}
+
+// NodeResourceList is the structured output of the node command.
+type NodeResourceList struct {
+	Items []NodeResource `json:"items"`
+}
+
+// PodResourceList is the structured output of the pod command.
+type PodResourceList struct {
+	Items []PodResource `json:"items"`
 }
 
 //NodeCapacity
@@ -154,16 +247,6 @@ func NodeCapacity(node *v1.Node) v1.ResourceList {
 	return allocatable
 }
 
-// getNodeMetricsByNodeName returns a map of node metrics where the keys are the particular node names
-func getNodeMetricsByNodeName(nodeMetricsList *metricsapi.NodeMetricsList) map[string]metricsapi.NodeMetrics {
-	nodeMetricsByName := make(map[string]metricsapi.NodeMetrics)
-	for _, metrics := range nodeMetricsList.Items {
-		nodeMetricsByName[metrics.Name] = metrics
-	}
-
-	return nodeMetricsByName
-}
-
 // // getNodeMetricsByPodName returns a map of node metrics where the keys are the particular pod names
 // func getPodMetricsByPodName(podMetricsList []metricsapi.PodMetrics) map[string]metricsapi.PodMetrics {
 // 	podMetricsByName := make(map[string]metricsapi.PodMetrics)
@@ -174,7 +257,11 @@ func getNodeMetricsByNodeName(nodeMetricsList *metricsapi.NodeMetricsList) map[s
 // 	return podMetricsByName
 // }
 
+//getPodMetrics sums the usage of the containers of a pod, nil if the metrics of the pod are unknown
 func getPodMetrics(m *metricsapi.PodMetrics) v1.ResourceList {
+	if m == nil {
+		return nil
+	}
 	podMetrics := make(v1.ResourceList)
 	for _, res := range metricsutil.MeasuredResources {
 		podMetrics[res], _ = resource.ParseQuantity("0")
@@ -191,10 +278,11 @@ func getPodMetrics(m *metricsapi.PodMetrics) v1.ResourceList {
 }
 
 //getNodeAllocatedResources https://github.com/kubernetes/dashboard/blob/d386ff60597b6eab0222f2c3c4aecf8e49b3014e/src/app/backend/resource/node/detail.go\#L171
-func getNodeAllocatedResources(node v1.Node, podList *v1.PodList, nodeMetricsList *metricsapi.NodeMetricsList, resourceType string) (NodeAllocatedResources, error) {
+//A nil usageMetrics leaves the usage unknown.
+func getNodeAllocatedResources(node v1.Node, pods []v1.Pod, usageMetrics *metricsapi.NodeMetrics) (NodeAllocatedResources, error) {
 	reqs, limits := map[v1.ResourceName]resource.Quantity{}, map[v1.ResourceName]resource.Quantity{}
 
-	for _, pod := range podList.Items {
+	for _, pod := range pods {
 		podReqs, podLimits, err := PodRequestsAndLimits(&pod)
 		if err != nil {
 			return NodeAllocatedResources{}, err
@@ -217,161 +305,77 @@ func getNodeAllocatedResources(node v1.Node, podList *v1.PodList, nodeMetricsLis
 		}
 	}
 
-	nodeMetricsByNodeName := getNodeMetricsByNodeName(nodeMetricsList)
-	usageMetrics := nodeMetricsByNodeName[node.Name]
-
 	capacity := NodeCapacity(&node)
-	var nodeAllocatedResources = NodeAllocatedResources{}
-	switch {
-	case resourceType == "cpu":
-		_cpuRequests, _cpuLimits := reqs[v1.ResourceCPU], limits[v1.ResourceCPU]
-		_cpuUsages := usageMetrics.Usage.Cpu().MilliValue()
-
-		cpuUsages := NewCpuResource(_cpuUsages)
-		cpuRequests := NewCpuResource(_cpuRequests.MilliValue())
-		cpuLimits := NewCpuResource(_cpuLimits.MilliValue())
-
-		nodeAllocatedResources = NodeAllocatedResources{
-			CPUResources{
-				CPUUsages:           cpuUsages,
-				CPURequests:         cpuRequests,
-				CPURequestsFraction: cpuRequests.calcPercentage(capacity.Cpu()),
-				CPULimits:           cpuLimits,
-				CPULimitsFraction:   cpuLimits.calcPercentage(capacity.Cpu()),
-				CPUCapacity:         NewCpuResource(capacity.Cpu().MilliValue()),
-			},
-			MemoryResources{},
-			GPUResources{},
-			PodResources{},
-		}
 
-	case resourceType == "memory":
-		_memoryRequests, _memoryLimits := reqs[v1.ResourceMemory], limits[v1.ResourceMemory]
-		_memoryUsages := usageMetrics.Usage.Memory().Value()
-
-		memoryUsages := NewMemoryResource(_memoryUsages)
-		memoryRequests := NewMemoryResource(_memoryRequests.Value())
-		memoryLimits := NewMemoryResource(_memoryLimits.Value())
-		nodeAllocatedResources = NodeAllocatedResources{
-			CPUResources{},
-			MemoryResources{
-				MemoryUsages:           memoryUsages,
-				MemoryRequests:         memoryRequests,
-				MemoryRequestsFraction: memoryRequests.calcPercentage(capacity.Memory()),
-				MemoryLimits:           memoryLimits,
-				MemoryLimitsFraction:   memoryLimits.calcPercentage(capacity.Memory()),
-				MemoryCapacity:         NewMemoryResource(capacity.Memory().Value()),
-			},
-			GPUResources{},
-			PodResources{},
-		}
-	case resourceType == "pod":
-		podCapacity := capacity.Pods().Value()
-		podFraction := calcPercentage(int64(len(podList.Items)), podCapacity)
-		nodeAllocatedResources = NodeAllocatedResources{
-			CPUResources{},
-			MemoryResources{},
-			GPUResources{},
-			PodResources{
-				AllocatedPods: len(podList.Items),
-				PodCapacity:   podCapacity,
-				PodFraction:   podFraction,
-			},
-		}
-	case resourceType == "gpu":
-		_nvidiaGpuCountsRequests, _nvidiaGpuCountsLimits := reqs[ResourceNvidiaGpuCounts], limits[ResourceNvidiaGpuCounts]
-		nvidiaGpuCountsRequests := _nvidiaGpuCountsRequests.Value()
-		nvidiaGpuCountsLimits := _nvidiaGpuCountsLimits.Value()
-		nvidiaGpuCountsCapacity := NewGpuResource(ResourceNvidiaGpuCounts, &capacity).Value()
-
-		// _aliyunGpuMemRequests, _aliyunGpuMemLimits := reqs[ResourceAliyunGpuMem], limits[ResourceAliyunGpuMem]
-		// aliyunGpuMemRequests := _aliyunGpuMemRequests.Value()
-		// aliyunGpuMemLimits := _aliyunGpuMemLimits.Value()
-		// aliyunGpuMemCapacity := NewGpuResource(ResourceAliyunGpuMem, &capacity).Value()
-
-		nodeAllocatedResources = NodeAllocatedResources{
-			CPUResources{},
-			MemoryResources{},
-			GPUResources{
-				NvidiaGpuCountsRequests:         nvidiaGpuCountsRequests,
-				NvidiaGpuCountsRequestsFraction: calcPercentage(nvidiaGpuCountsRequests, nvidiaGpuCountsCapacity),
-				NvidiaGpuCountsLimits:           nvidiaGpuCountsLimits,
-				NvidiaGpuCountsLimitsFraction:   calcPercentage(nvidiaGpuCountsLimits, nvidiaGpuCountsCapacity),
-				NvidiaGpuCountsCapacity:         nvidiaGpuCountsCapacity,
-				// AliyunGpuMemRequests:            aliyunGpuMemRequests,
-				// AliyunGpuMemRequestsFraction:    calcPercentage(aliyunGpuMemRequests, aliyunGpuMemCapacity),
-				// AliyunGpuMemLimits:              aliyunGpuMemLimits,
-				// AliyunGpuMemLimitsFraction:      calcPercentage(aliyunGpuMemLimits, aliyunGpuMemCapacity),
-				// AliyunGpuMemCapacity:            aliyunGpuMemCapacity,
-			},
-			PodResources{},
-		}
-	default:
-		_cpuRequests, _cpuLimits, _memoryRequests, _memoryLimits := reqs[v1.ResourceCPU], limits[v1.ResourceCPU],
-			reqs[v1.ResourceMemory], limits[v1.ResourceMemory]
-		_cpuUsages, _memoryUsages := usageMetrics.Usage.Cpu().MilliValue(), usageMetrics.Usage.Memory().Value()
-
-		cpuUsages := NewCpuResource(_cpuUsages)
-		cpuRequests := NewCpuResource(_cpuRequests.MilliValue())
-		cpuLimits := NewCpuResource(_cpuLimits.MilliValue())
-
-		memoryUsages := NewMemoryResource(_memoryUsages)
-		memoryRequests := NewMemoryResource(_memoryRequests.Value())
-		memoryLimits := NewMemoryResource(_memoryLimits.Value())
-		podCapacity := capacity.Pods().Value()
-		podFraction := calcPercentage(int64(len(podList.Items)), podCapacity)
-
-		_nvidiaGpuCountsRequests, _nvidiaGpuCountsLimits := reqs[ResourceNvidiaGpuCounts], limits[ResourceNvidiaGpuCounts]
-		nvidiaGpuCountsRequests := _nvidiaGpuCountsRequests.Value()
-		nvidiaGpuCountsLimits := _nvidiaGpuCountsLimits.Value()
-		nvidiaGpuCountsCapacity := NewGpuResource(ResourceNvidiaGpuCounts, &capacity).Value()
-
-		// _aliyunGpuMemRequests, _aliyunGpuMemLimits := reqs[ResourceAliyunGpuMem], limits[ResourceAliyunGpuMem]
-		// aliyunGpuMemRequests := _aliyunGpuMemRequests.Value()
-		// aliyunGpuMemLimits := _aliyunGpuMemLimits.Value()
-		// aliyunGpuMemCapacity := NewGpuResource(ResourceAliyunGpuMem, &capacity).Value()
-
-		nodeAllocatedResources = NodeAllocatedResources{
-			CPUResources{
-				CPUUsages:           cpuUsages,
-				CPURequests:         cpuRequests,
-				CPURequestsFraction: cpuRequests.calcPercentage(capacity.Cpu()),
-				CPULimits:           cpuLimits,
-				CPULimitsFraction:   cpuLimits.calcPercentage(capacity.Cpu()),
-				CPUCapacity:         NewCpuResource(capacity.Cpu().MilliValue()),
-			},
-			MemoryResources{
-				MemoryUsages:           memoryUsages,
-				MemoryRequests:         memoryRequests,
-				MemoryRequestsFraction: memoryRequests.calcPercentage(capacity.Memory()),
-				MemoryLimits:           memoryLimits,
-				MemoryLimitsFraction:   memoryLimits.calcPercentage(capacity.Memory()),
-				MemoryCapacity:         NewMemoryResource(capacity.Memory().Value()),
-			},
-			GPUResources{
-				NvidiaGpuCountsRequests:         nvidiaGpuCountsRequests,
-				NvidiaGpuCountsRequestsFraction: calcPercentage(nvidiaGpuCountsRequests, nvidiaGpuCountsCapacity),
-				NvidiaGpuCountsLimits:           nvidiaGpuCountsLimits,
-				NvidiaGpuCountsLimitsFraction:   calcPercentage(nvidiaGpuCountsLimits, nvidiaGpuCountsCapacity),
-				NvidiaGpuCountsCapacity:         nvidiaGpuCountsCapacity,
-				// AliyunGpuMemRequests:            aliyunGpuMemRequests,
-				// AliyunGpuMemRequestsFraction:    calcPercentage(aliyunGpuMemRequests, aliyunGpuMemCapacity),
-				// AliyunGpuMemLimits:              aliyunGpuMemLimits,
-				// AliyunGpuMemLimitsFraction:      calcPercentage(aliyunGpuMemLimits, aliyunGpuMemCapacity),
-				// AliyunGpuMemCapacity:            aliyunGpuMemCapacity,
-			},
-			PodResources{
-				AllocatedPods: len(podList.Items),
-				PodCapacity:   podCapacity,
-				PodFraction:   podFraction,
-			},
-		}
+	_cpuRequests, _cpuLimits, _memoryRequests, _memoryLimits := reqs[v1.ResourceCPU], limits[v1.ResourceCPU],
+		reqs[v1.ResourceMemory], limits[v1.ResourceMemory]
+
+	var cpuUsages *CpuResource
+	var memoryUsages *MemoryResource
+	if usageMetrics != nil {
+		cpuUsages = NewCpuResource(usageMetrics.Usage.Cpu().MilliValue())
+		memoryUsages = NewMemoryResource(usageMetrics.Usage.Memory().Value())
 	}
-	return nodeAllocatedResources, nil
+	cpuRequests := NewCpuResource(_cpuRequests.MilliValue())
+	cpuLimits := NewCpuResource(_cpuLimits.MilliValue())
+
+	memoryRequests := NewMemoryResource(_memoryRequests.Value())
+	memoryLimits := NewMemoryResource(_memoryLimits.Value())
+	podCapacity := capacity.Pods().Value()
+	podFraction := calcPercentage(int64(len(pods)), podCapacity)
+
+	_nvidiaGpuCountsRequests, _nvidiaGpuCountsLimits := reqs[ResourceNvidiaGpuCounts], limits[ResourceNvidiaGpuCounts]
+	nvidiaGpuCountsRequests := _nvidiaGpuCountsRequests.Value()
+	nvidiaGpuCountsLimits := _nvidiaGpuCountsLimits.Value()
+	nvidiaGpuCountsCapacity := NewGpuResource(ResourceNvidiaGpuCounts, &capacity).Value()
+
+	// _aliyunGpuMemRequests, _aliyunGpuMemLimits := reqs[ResourceAliyunGpuMem], limits[ResourceAliyunGpuMem]
+	// aliyunGpuMemRequests := _aliyunGpuMemRequests.Value()
+	// aliyunGpuMemLimits := _aliyunGpuMemLimits.Value()
+	// aliyunGpuMemCapacity := NewGpuResource(ResourceAliyunGpuMem, &capacity).Value()
+
+	return NodeAllocatedResources{
+		CPUResources{
+			CPUUsages:           cpuUsages,
+			CPURequests:         cpuRequests,
+			CPURequestsFraction: cpuRequests.calcPercentage(capacity.Cpu()),
+			CPULimits:           cpuLimits,
+			CPULimitsFraction:   cpuLimits.calcPercentage(capacity.Cpu()),
+			CPUCapacity:         NewCpuResource(capacity.Cpu().MilliValue()),
+		},
+		MemoryResources{
+			MemoryUsages:           memoryUsages,
+			MemoryRequests:         memoryRequests,
+			MemoryRequestsFraction: memoryRequests.calcPercentage(capacity.Memory()),
+			MemoryLimits:           memoryLimits,
+			MemoryLimitsFraction:   memoryLimits.calcPercentage(capacity.Memory()),
+			MemoryCapacity:         NewMemoryResource(capacity.Memory().Value()),
+		},
+		GPUResources{
+			NvidiaGpuCountsRequests:         nvidiaGpuCountsRequests,
+			NvidiaGpuCountsRequestsFraction: calcPercentage(nvidiaGpuCountsRequests, nvidiaGpuCountsCapacity),
+			NvidiaGpuCountsLimits:           nvidiaGpuCountsLimits,
+			NvidiaGpuCountsLimitsFraction:   calcPercentage(nvidiaGpuCountsLimits, nvidiaGpuCountsCapacity),
+			NvidiaGpuCountsCapacity:         nvidiaGpuCountsCapacity,
+			// AliyunGpuMemRequests:            aliyunGpuMemRequests,
+			// AliyunGpuMemRequestsFraction:    calcPercentage(aliyunGpuMemRequests, aliyunGpuMemCapacity),
+			// AliyunGpuMemLimits:              aliyunGpuMemLimits,
+			// AliyunGpuMemLimitsFraction:      calcPercentage(aliyunGpuMemLimits, aliyunGpuMemCapacity),
+			// AliyunGpuMemCapacity:            aliyunGpuMemCapacity,
+		},
+		PodResources{
+			AllocatedPods: len(pods),
+			PodCapacity:   podCapacity,
+			PodFraction:   podFraction,
+		},
+		getExtendedResources(reqs, limits, capacity),
+		getStorageResources(reqs, limits, capacity, isEphemeralStorageResourceName),
+		getStorageResources(reqs, limits, capacity, isHugePagesResourceName),
+	}, nil
 }
 
-//getPodAllocatedResources
-func getPodAllocatedResources(pod *v1.Pod, podmetric *metricsapi.PodMetrics, resourceType string) (PodAllocatedResources, error) {
+//getPodAllocatedResources returns the allocated resources of pod, a nil podmetric leaves the usage unknown
+func getPodAllocatedResources(pod *v1.Pod, podmetric *metricsapi.PodMetrics) (PodAllocatedResources, error) {
 
 	reqs, limits := map[v1.ResourceName]resource.Quantity{}, map[v1.ResourceName]resource.Quantity{}
 
@@ -395,92 +399,104 @@ func getPodAllocatedResources(pod *v1.Pod, podmetric *metricsapi.PodMetrics, res
 			limits[podLimitName] = value
 		}
 	}
-	//podMetricsByPodName := getPodMetricsByPodName(podMetricsList)
-	//usageMetrics := podMetricsByPodName[pod.Name]
 	usageMetrics := getPodMetrics(podmetric)
 
-	var podAllocatedResources = PodAllocatedResources{}
-	switch {
-	case resourceType == "cpu":
-		_cpuRequests, _cpuLimits := reqs[v1.ResourceCPU], limits[v1.ResourceCPU]
-		_cpuUsages := usageMetrics[v1.ResourceCPU]
+	return allocatedResources(reqs, limits, usageMetrics), nil
+}
 
-		cpuUsages := NewCpuResource(_cpuUsages.MilliValue())
-		cpuRequests := NewCpuResource(_cpuRequests.MilliValue())
-		cpuLimits := NewCpuResource(_cpuLimits.MilliValue())
+//getContainerAllocatedResources returns the allocated resources of every container and init container of pod.
+//A nil podmetric leaves the usage unknown, a container missing from podmetric, e.g. an init container, uses nothing.
+func getContainerAllocatedResources(pod *v1.Pod, podmetric *metricsapi.PodMetrics) []ContainerResource {
+	var usages map[string]v1.ResourceList
+	if podmetric != nil {
+		usages = make(map[string]v1.ResourceList)
+		for _, c := range pod.Spec.InitContainers {
+			usages[c.Name] = v1.ResourceList{}
+		}
+		for _, c := range pod.Spec.Containers {
+			usages[c.Name] = v1.ResourceList{}
+		}
+		for _, c := range podmetric.Containers {
+			usages[c.Name] = c.Usage
+		}
+	}
+
+	// the other init containers have terminated once the pod is running
+	sidecars := make(map[string]bool)
+	if pod.Status.Phase == v1.PodRunning {
+		for _, status := range pod.Status.InitContainerStatuses {
+			sidecars[status.Name] = status.State.Running != nil
+		}
+	}
+
+	var containers []ContainerResource
+	for _, container := range pod.Spec.InitContainers {
+		containers = append(containers, ContainerResource{
+			Name:                  container.Name,
+			Init:                  true,
+			Sidecar:               sidecars[container.Name],
+			PodAllocatedResources: allocatedResources(container.Resources.Requests, container.Resources.Limits, usages[container.Name]),
+		})
+	}
+	for _, container := range pod.Spec.Containers {
+		containers = append(containers, ContainerResource{
+			Name:                  container.Name,
+			PodAllocatedResources: allocatedResources(container.Resources.Requests, container.Resources.Limits, usages[container.Name]),
+		})
+	}
+	return containers
+}
 
-		podAllocatedResources = PodAllocatedResources{
+//allocatedResources builds the allocated resources of a pod or container from its requests, limits and usage.
+//A nil usageMetrics leaves the usage unknown.
+func allocatedResources(reqs, limits, usageMetrics v1.ResourceList) PodAllocatedResources {
+	_cpuRequests, _cpuLimits, _memoryRequests, _memoryLimits := reqs[v1.ResourceCPU], limits[v1.ResourceCPU],
+		reqs[v1.ResourceMemory], limits[v1.ResourceMemory]
+
+	var cpuUsages *CpuResource
+	var memoryUsages *MemoryResource
+	if usageMetrics != nil {
+		_cpuUsages, _memoryUsages := usageMetrics[v1.ResourceCPU], usageMetrics[v1.ResourceMemory]
+		cpuUsages = NewCpuResource(_cpuUsages.MilliValue())
+		memoryUsages = NewMemoryResource(_memoryUsages.Value())
+	}
+	cpuRequests := NewCpuResource(_cpuRequests.MilliValue())
+	cpuLimits := NewCpuResource(_cpuLimits.MilliValue())
+
+	memoryRequests := NewMemoryResource(_memoryRequests.Value())
+	memoryLimits := NewMemoryResource(_memoryLimits.Value())
+
+	_nvidiaGpuCountsRequests, _nvidiaGpuCountsLimits := reqs[ResourceNvidiaGpuCounts], limits[ResourceNvidiaGpuCounts]
+	nvidiaGpuCountsRequests := _nvidiaGpuCountsRequests.Value()
+	nvidiaGpuCountsLimits := _nvidiaGpuCountsLimits.Value()
+
+	// _aliyunGpuMemRequests, _aliyunGpuMemLimits := reqs[ResourceAliyunGpuMem], limits[ResourceAliyunGpuMem]
+	// aliyunGpuMemRequests := _aliyunGpuMemRequests.Value()
+	// aliyunGpuMemLimits := _aliyunGpuMemLimits.Value()
+
+	return PodAllocatedResources{
+		PodCPUResources{
 			CPUUsages:         cpuUsages,
 			CPUUsagesFraction: cpuUsages.calcPercentage(&_cpuLimits),
 			CPURequests:       cpuRequests,
 			CPULimits:         cpuLimits,
-		}
-	case resourceType == "memory":
-		_memoryRequests, _memoryLimits := reqs[v1.ResourceMemory], limits[v1.ResourceMemory]
-		_memoryUsages := usageMetrics[v1.ResourceMemory]
-
-		memoryUsages := NewMemoryResource(_memoryUsages.Value())
-		memoryRequests := NewMemoryResource(_memoryRequests.Value())
-		memoryLimits := NewMemoryResource(_memoryLimits.Value())
-		podAllocatedResources = PodAllocatedResources{
+		},
+		PodMemoryResources{
 			MemoryUsages:         memoryUsages,
 			MemoryUsagesFraction: memoryUsages.calcPercentage(&_memoryLimits),
 			MemoryRequests:       memoryRequests,
 			MemoryLimits:         memoryLimits,
-		}
-	case resourceType == "gpu":
-		_nvidiaGpuCountsRequests, _nvidiaGpuCountsLimits := reqs[ResourceNvidiaGpuCounts], limits[ResourceNvidiaGpuCounts]
-		nvidiaGpuCountsRequests := _nvidiaGpuCountsRequests.Value()
-		nvidiaGpuCountsLimits := _nvidiaGpuCountsLimits.Value()
-
-		// _aliyunGpuMemRequests, _aliyunGpuMemLimits := reqs[ResourceAliyunGpuMem], limits[ResourceAliyunGpuMem]
-		// aliyunGpuMemRequests := _aliyunGpuMemRequests.Value()
-		// aliyunGpuMemLimits := _aliyunGpuMemLimits.Value()
-
-		podAllocatedResources = PodAllocatedResources{
-			NvidiaGpuCountsRequests: nvidiaGpuCountsRequests,
-			NvidiaGpuCountsLimits:   nvidiaGpuCountsLimits,
-			// AliyunGpuMemRequests:    aliyunGpuMemRequests,
-			// AliyunGpuMemLimits:      aliyunGpuMemLimits,
-		}
-
-	default:
-		_cpuRequests, _cpuLimits, _memoryRequests, _memoryLimits := reqs[v1.ResourceCPU], limits[v1.ResourceCPU],
-			reqs[v1.ResourceMemory], limits[v1.ResourceMemory]
-		_cpuUsages, _memoryUsages := usageMetrics[v1.ResourceCPU], usageMetrics[v1.ResourceMemory]
-
-		cpuUsages := NewCpuResource(_cpuUsages.MilliValue())
-		cpuRequests := NewCpuResource(_cpuRequests.MilliValue())
-		cpuLimits := NewCpuResource(_cpuLimits.MilliValue())
-
-		memoryUsages := NewMemoryResource(_memoryUsages.Value())
-		memoryRequests := NewMemoryResource(_memoryRequests.Value())
-		memoryLimits := NewMemoryResource(_memoryLimits.Value())
-
-		_nvidiaGpuCountsRequests, _nvidiaGpuCountsLimits := reqs[ResourceNvidiaGpuCounts], limits[ResourceNvidiaGpuCounts]
-		nvidiaGpuCountsRequests := _nvidiaGpuCountsRequests.Value()
-		nvidiaGpuCountsLimits := _nvidiaGpuCountsLimits.Value()
-
-		// _aliyunGpuMemRequests, _aliyunGpuMemLimits := reqs[ResourceAliyunGpuMem], limits[ResourceAliyunGpuMem]
-		// aliyunGpuMemRequests := _aliyunGpuMemRequests.Value()
-		// aliyunGpuMemLimits := _aliyunGpuMemLimits.Value()
-
-		podAllocatedResources = PodAllocatedResources{
-			CPUUsages:               cpuUsages,
-			CPUUsagesFraction:       cpuUsages.calcPercentage(&_cpuLimits),
-			CPURequests:             cpuRequests,
-			CPULimits:               cpuLimits,
-			MemoryUsages:            memoryUsages,
-			MemoryUsagesFraction:    memoryUsages.calcPercentage(&_memoryLimits),
-			MemoryRequests:          memoryRequests,
-			MemoryLimits:            memoryLimits,
+		},
+		PodGPUResources{
 			NvidiaGpuCountsRequests: nvidiaGpuCountsRequests,
 			NvidiaGpuCountsLimits:   nvidiaGpuCountsLimits,
 			// AliyunGpuMemRequests:    aliyunGpuMemRequests,
 			// AliyunGpuMemLimits:      aliyunGpuMemLimits,
-		}
+		},
+		getPodExtendedResources(reqs, limits),
+		getPodStorageResources(reqs, limits, isEphemeralStorageResourceName),
+		getPodStorageResources(reqs, limits, isHugePagesResourceName),
 	}
-	return podAllocatedResources, nil
 }
 
 // PodRequestsAndLimits returns a dictionary of all defined resources summed up for all
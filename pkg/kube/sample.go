@@ -0,0 +1,281 @@
+package kube
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics"
+)
+
+// UsageStats are the statistics of the usage samples of a node or pod taken by a UsageSampler.
+type UsageStats struct {
+	// Samples is the number of distinct samples, the same metrics-server sample polled twice counts once.
+	Samples int `json:"samples"`
+
+	CPU    CPUUsageStats    `json:"cpu"`
+	Memory MemoryUsageStats `json:"memory"`
+}
+
+// CPUUsageStats are the statistics of the cpu usage samples in millicores.
+type CPUUsageStats struct {
+	Min *CpuResource `json:"min"`
+	Avg *CpuResource `json:"avg"`
+	P95 *CpuResource `json:"p95"`
+	Max *CpuResource `json:"max"`
+}
+
+// MemoryUsageStats are the statistics of the memory usage samples in bytes.
+type MemoryUsageStats struct {
+	Min *MemoryResource `json:"min"`
+	Avg *MemoryResource `json:"avg"`
+	P95 *MemoryResource `json:"p95"`
+	Max *MemoryResource `json:"max"`
+}
+
+// usageSamples are the usage samples of a node, or of a pod with its containers
+type usageSamples struct {
+	// last is the timestamp of the last sample, to skip the samples polled twice
+	last time.Time
+
+	cpu, memory []int64
+
+	// containers are the samples of the containers of a pod, in the order they first appeared
+	containers     []string
+	containerUsage map[string]*usageSamples
+}
+
+// UsageSampler is a UsageProvider polling another UsageProvider every interval for a duration, instead of
+// taking a single sample, skipping the polls which fail. The usage it provides is the average of the samples,
+// their statistics are set on the nodes and pods by SetNodeUsageStats and SetPodUsageStats.
+type UsageSampler struct {
+	usage    UsageProvider
+	duration time.Duration
+	interval time.Duration
+
+	nodes map[string]*usageSamples
+	pods  map[string]*usageSamples
+}
+
+// SampleUsage makes the client poll its usage provider every interval for duration and returns the sampler.
+// The contexts of the calls reading the usage must outlast duration.
+func (k *KubeClient) SampleUsage(duration, interval time.Duration) *UsageSampler {
+	s := &UsageSampler{usage: k.usage, duration: duration, interval: interval}
+	k.usage = s
+	return s
+}
+
+//NodeMetrics returns the average usage of the nodes over the samples
+func (s *UsageSampler) NodeMetrics(ctx context.Context, resourceName string, selector labels.Selector) (*metricsapi.NodeMetricsList, error) {
+	s.nodes = map[string]*usageSamples{}
+	var names []string
+	err := s.poll(ctx, func() error {
+		metrics, err := s.usage.NodeMetrics(ctx, resourceName, selector)
+		if err != nil {
+			return err
+		}
+		for _, m := range metrics.Items {
+			samples, ok := s.nodes[m.Name]
+			if !ok {
+				samples = &usageSamples{}
+				s.nodes[m.Name] = samples
+				names = append(names, m.Name)
+			}
+			if !m.Timestamp.Time.IsZero() && m.Timestamp.Time.Equal(samples.last) {
+				continue
+			}
+			samples.last = m.Timestamp.Time
+			samples.add(m.Usage)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &metricsapi.NodeMetricsList{}
+	for _, name := range names {
+		metrics.Items = append(metrics.Items, metricsapi.NodeMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Timestamp:  metav1.Now(),
+			Window:     metav1.Duration{Duration: s.duration},
+			Usage:      s.nodes[name].average(),
+		})
+	}
+	return metrics, nil
+}
+
+//PodMetrics returns the average usage of the containers of the pods over the samples
+func (s *UsageSampler) PodMetrics(ctx context.Context, namespace, resourceName string, allNamespaces bool, labelSelector labels.Selector, fieldSelector fields.Selector) (*metricsapi.PodMetricsList, error) {
+	s.pods = map[string]*usageSamples{}
+	var pods []metav1.ObjectMeta
+	err := s.poll(ctx, func() error {
+		metrics, err := s.usage.PodMetrics(ctx, namespace, resourceName, allNamespaces, labelSelector, fieldSelector)
+		if err != nil {
+			return err
+		}
+		for _, m := range metrics.Items {
+			key := m.Namespace + "/" + m.Name
+			samples, ok := s.pods[key]
+			if !ok {
+				samples = &usageSamples{containerUsage: map[string]*usageSamples{}}
+				s.pods[key] = samples
+				pods = append(pods, metav1.ObjectMeta{Name: m.Name, Namespace: m.Namespace})
+			}
+			if !m.Timestamp.Time.IsZero() && m.Timestamp.Time.Equal(samples.last) {
+				continue
+			}
+			samples.last = m.Timestamp.Time
+
+			total := corev1.ResourceList{}
+			for _, c := range m.Containers {
+				container, ok := samples.containerUsage[c.Name]
+				if !ok {
+					container = &usageSamples{}
+					samples.containerUsage[c.Name] = container
+					samples.containers = append(samples.containers, c.Name)
+				}
+				container.add(c.Usage)
+				for name, quantity := range c.Usage {
+					sum := total[name]
+					sum.Add(quantity)
+					total[name] = sum
+				}
+			}
+			samples.add(total)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &metricsapi.PodMetricsList{}
+	for _, pod := range pods {
+		samples := s.pods[pod.Namespace+"/"+pod.Name]
+		m := metricsapi.PodMetrics{
+			ObjectMeta: pod,
+			Timestamp:  metav1.Now(),
+			Window:     metav1.Duration{Duration: s.duration},
+		}
+		for _, c := range samples.containers {
+			m.Containers = append(m.Containers, metricsapi.ContainerMetrics{Name: c, Usage: samples.containerUsage[c].average()})
+		}
+		metrics.Items = append(metrics.Items, m)
+	}
+	return metrics, nil
+}
+
+// SetNodeUsageStats sets the statistics of the usage samples of nodes
+func (s *UsageSampler) SetNodeUsageStats(nodes []NodeResource) {
+	for i := range nodes {
+		if samples, ok := s.nodes[nodes[i].Name]; ok {
+			nodes[i].UsageStats = samples.stats()
+		}
+	}
+}
+
+// SetPodUsageStats sets the statistics of the usage samples of pods, summed up over their containers
+func (s *UsageSampler) SetPodUsageStats(pods []PodResource) {
+	for i := range pods {
+		if samples, ok := s.pods[pods[i].Namespace+"/"+pods[i].Name]; ok {
+			pods[i].UsageStats = samples.stats()
+		}
+	}
+}
+
+//poll calls sample right away, then every interval until duration is over. A failed sample is skipped,
+//e.g. a timeout of metrics-server, poll fails only if no sample succeeded, returning the last error.
+func (s *UsageSampler) poll(ctx context.Context, sample func() error) error {
+	err := sample()
+	sampled := err == nil
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for n := s.duration / s.interval; n > 0; n-- {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if e := sample(); e != nil {
+			err = e
+			continue
+		}
+		sampled = true
+	}
+	if !sampled {
+		return err
+	}
+	return nil
+}
+
+//add appends the cpu and memory of usage to the samples
+func (u *usageSamples) add(usage corev1.ResourceList) {
+	u.cpu = append(u.cpu, usage.Cpu().MilliValue())
+	u.memory = append(u.memory, usage.Memory().Value())
+}
+
+//average returns the average cpu and memory of the samples
+func (u *usageSamples) average() corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    *resource.NewMilliQuantity(average(u.cpu), resource.DecimalSI),
+		corev1.ResourceMemory: *resource.NewQuantity(average(u.memory), resource.BinarySI),
+	}
+}
+
+//stats returns the statistics of the samples
+func (u *usageSamples) stats() *UsageStats {
+	cpu, memory := sorted(u.cpu), sorted(u.memory)
+	if len(cpu) == 0 {
+		return &UsageStats{}
+	}
+	return &UsageStats{
+		Samples: len(cpu),
+		CPU: CPUUsageStats{
+			Min: NewCpuResource(cpu[0]),
+			Avg: NewCpuResource(average(cpu)),
+			P95: NewCpuResource(percentile(cpu, 95)),
+			Max: NewCpuResource(cpu[len(cpu)-1]),
+		},
+		Memory: MemoryUsageStats{
+			Min: NewMemoryResource(memory[0]),
+			Avg: NewMemoryResource(average(memory)),
+			P95: NewMemoryResource(percentile(memory, 95)),
+			Max: NewMemoryResource(memory[len(memory)-1]),
+		},
+	}
+}
+
+//sorted returns a sorted copy of values
+func sorted(values []int64) []int64 {
+	s := append([]int64(nil), values...)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	return s
+}
+
+//average returns the rounded average of values, 0 without values
+func average(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+	return int64(math.Round(sum / float64(len(values))))
+}
+
+//percentile returns the nearest-rank percentile p of the sorted values
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,220 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics"
+)
+
+// fakeSample is what a poll of fakeUsage returns
+type fakeSample struct {
+	second int
+	cpu    int64
+	memory int64
+	err    error
+}
+
+// fakeUsage is a UsageProvider returning its samples in turn, the usage of a node named node and of a pod
+// default/pod with the containers app and sidecar sharing the usage
+type fakeUsage struct {
+	samples []fakeSample
+	polls   int
+}
+
+//next returns the next sample, the last one once all were returned
+func (u *fakeUsage) next() fakeSample {
+	i := u.polls
+	if i >= len(u.samples) {
+		i = len(u.samples) - 1
+	}
+	u.polls++
+	return u.samples[i]
+}
+
+//usage returns the resource list of cpu millicores and memory bytes
+func usage(cpu, memory int64) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+		corev1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+	}
+}
+
+//NodeMetrics
+func (u *fakeUsage) NodeMetrics(ctx context.Context, resourceName string, selector labels.Selector) (*metricsapi.NodeMetricsList, error) {
+	s := u.next()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &metricsapi.NodeMetricsList{Items: []metricsapi.NodeMetrics{{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Timestamp:  metav1.NewTime(time.Unix(int64(s.second), 0)),
+		Usage:      usage(s.cpu, s.memory),
+	}}}, nil
+}
+
+//PodMetrics
+func (u *fakeUsage) PodMetrics(ctx context.Context, namespace, resourceName string, allNamespaces bool, labelSelector labels.Selector, fieldSelector fields.Selector) (*metricsapi.PodMetricsList, error) {
+	s := u.next()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &metricsapi.PodMetricsList{Items: []metricsapi.PodMetrics{{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+		Timestamp:  metav1.NewTime(time.Unix(int64(s.second), 0)),
+		Containers: []metricsapi.ContainerMetrics{
+			{Name: "app", Usage: usage(s.cpu/2, s.memory/2)},
+			{Name: "sidecar", Usage: usage(s.cpu/2, s.memory/2)},
+		},
+	}}}, nil
+}
+
+//testSampler returns a sampler polling u 6 times
+func testSampler(u *fakeUsage) *UsageSampler {
+	return &UsageSampler{usage: u, duration: 5 * time.Millisecond, interval: time.Millisecond}
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		values []int64
+		p      float64
+		want   int64
+	}{
+		{[]int64{7}, 95, 7},
+		{[]int64{1, 2}, 50, 1},
+		{[]int64{1, 2}, 95, 2},
+		{[]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 95, 10},
+		{[]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 90, 9},
+		{[]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 0, 1},
+	}
+	for _, test := range tests {
+		if got := percentile(test.values, test.p); got != test.want {
+			t.Errorf("percentile(%v, %v): got %d, want %d", test.values, test.p, got, test.want)
+		}
+	}
+}
+
+func TestAverage(t *testing.T) {
+	tests := []struct {
+		values []int64
+		want   int64
+	}{
+		{nil, 0},
+		{[]int64{5}, 5},
+		{[]int64{1, 2}, 2},
+		{[]int64{1, 1, 2}, 1},
+		{[]int64{100, 200, 300}, 200},
+	}
+	for _, test := range tests {
+		if got := average(test.values); got != test.want {
+			t.Errorf("average(%v): got %d, want %d", test.values, got, test.want)
+		}
+	}
+}
+
+func TestUsageSamplesStats(t *testing.T) {
+	if stats := (&usageSamples{}).stats(); stats.Samples != 0 || stats.CPU.Avg != nil {
+		t.Errorf("got %+v without samples, want no statistics", stats)
+	}
+
+	u := &usageSamples{cpu: []int64{300, 100, 200}, memory: []int64{3 << 20, 1 << 20, 2 << 20}}
+	stats := u.stats()
+	if stats.Samples != 3 {
+		t.Errorf("got %d samples, want 3", stats.Samples)
+	}
+	if cpu := stats.CPU; cpu.Min.MilliValue() != 100 || cpu.Avg.MilliValue() != 200 || cpu.P95.MilliValue() != 300 || cpu.Max.MilliValue() != 300 {
+		t.Errorf("got cpu %s/%s/%s/%s, want 100m/200m/300m/300m", cpu.Min, cpu.Avg, cpu.P95, cpu.Max)
+	}
+	if memory := stats.Memory; memory.Min.Value() != 1<<20 || memory.Avg.Value() != 2<<20 || memory.Max.Value() != 3<<20 {
+		t.Errorf("got memory %s/%s/%s, want 1Mi/2Mi/3Mi", memory.Min, memory.Avg, memory.Max)
+	}
+	// the samples are not sorted in place
+	if u.cpu[0] != 300 {
+		t.Errorf("got the samples sorted in place, want them in the order they were taken")
+	}
+}
+
+func TestUsageSamplerNodeMetrics(t *testing.T) {
+	failed := errors.New("metrics-server timed out")
+	u := &fakeUsage{samples: []fakeSample{
+		{second: 1, cpu: 100, memory: 1 << 20},
+		// the same sample of metrics-server polled twice counts once
+		{second: 1, cpu: 100, memory: 1 << 20},
+		// a failed poll is skipped
+		{err: failed},
+		{second: 2, cpu: 300, memory: 3 << 20},
+		{err: failed},
+		{second: 3, cpu: 200, memory: 2 << 20},
+	}}
+	s := testSampler(u)
+	metrics, err := s.NodeMetrics(context.Background(), "", labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.polls != 6 {
+		t.Errorf("got %d polls, want 6", u.polls)
+	}
+	if len(metrics.Items) != 1 || metrics.Items[0].Name != "node" {
+		t.Fatalf("got %+v, want the node node", metrics.Items)
+	}
+	if cpu := metrics.Items[0].Usage.Cpu().MilliValue(); cpu != 200 {
+		t.Errorf("got an average cpu of %dm, want 200m", cpu)
+	}
+
+	nodes := []NodeResource{{Name: "node"}, {Name: "other"}}
+	s.SetNodeUsageStats(nodes)
+	if stats := nodes[0].UsageStats; stats == nil || stats.Samples != 3 || stats.CPU.Min.MilliValue() != 100 || stats.CPU.Max.MilliValue() != 300 {
+		t.Errorf("got the statistics %+v, want 3 samples from 100m to 300m", stats)
+	}
+	if nodes[1].UsageStats != nil {
+		t.Errorf("got statistics for a node without samples, want none")
+	}
+}
+
+func TestUsageSamplerPodMetrics(t *testing.T) {
+	u := &fakeUsage{samples: []fakeSample{
+		{err: errors.New("metrics-server timed out")},
+		{second: 1, cpu: 100, memory: 2 << 20},
+		{second: 2, cpu: 300, memory: 4 << 20},
+	}}
+	s := testSampler(u)
+	metrics, err := s.PodMetrics(context.Background(), "default", "", false, labels.Everything(), fields.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.Items) != 1 || len(metrics.Items[0].Containers) != 2 {
+		t.Fatalf("got %+v, want the pod default/pod with 2 containers", metrics.Items)
+	}
+	if app := metrics.Items[0].Containers[0]; app.Name != "app" || app.Usage.Cpu().MilliValue() != 100 || app.Usage.Memory().Value() != 3<<19 {
+		t.Errorf("got container %s using %s and %s, want app using 100m and 1536Ki", app.Name, app.Usage.Cpu(), app.Usage.Memory())
+	}
+
+	// the statistics of a pod are summed up over its containers
+	pods := []PodResource{{Namespace: "default", Name: "pod"}}
+	s.SetPodUsageStats(pods)
+	if stats := pods[0].UsageStats; stats == nil || stats.Samples != 2 || stats.CPU.Max.MilliValue() != 300 || stats.Memory.Min.Value() != 2<<20 {
+		t.Errorf("got the statistics %+v, want 2 samples up to 300m from 2Mi", stats)
+	}
+}
+
+func TestUsageSamplerFails(t *testing.T) {
+	failed := errors.New("metrics-server timed out")
+	s := testSampler(&fakeUsage{samples: []fakeSample{{err: errors.New("first")}, {err: failed}}})
+	if _, err := s.NodeMetrics(context.Background(), "", labels.Everything()); err != failed {
+		t.Errorf("got error %v, want the last error %v", err, failed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s = testSampler(&fakeUsage{samples: []fakeSample{{second: 1, cpu: 100}}})
+	if _, err := s.NodeMetrics(ctx, "", labels.Everything()); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,239 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SnapshotVersion is the version of the snapshot file format, increased on incompatible changes.
+const SnapshotVersion = 1
+
+// Snapshot is the computed resources of the nodes and pods of a cluster at a point in time.
+type Snapshot struct {
+	Version int       `json:"version"`
+	Time    time.Time `json:"time"`
+
+	// Usage is false if the snapshot was taken without the usage, which is then unknown.
+	Usage bool `json:"usage"`
+
+	Nodes []NodeResource `json:"nodes"`
+
+	// Pods are the pods of all namespaces that are neither succeeded nor failed.
+	Pods []PodResource `json:"pods"`
+}
+
+// Statuses of a node or namespace in a SnapshotDiff. A node or namespace is changed when its pod count,
+// requests or limits differ, the usage differing between almost any two snapshots.
+const (
+	DiffAdded     = "added"
+	DiffRemoved   = "removed"
+	DiffChanged   = "changed"
+	DiffUnchanged = "unchanged"
+)
+
+// CPUDelta is a cpu value in two snapshots, nil if unknown or missing.
+type CPUDelta struct {
+	Before *CpuResource `json:"before"`
+	After  *CpuResource `json:"after"`
+}
+
+// MemoryDelta is a memory value in two snapshots, nil if unknown or missing.
+type MemoryDelta struct {
+	Before *MemoryResource `json:"before"`
+	After  *MemoryResource `json:"after"`
+}
+
+// CountDelta is a number of pods in two snapshots.
+type CountDelta struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+}
+
+// ResourceDelta is the change of the resources of a node or namespace between two snapshots.
+type ResourceDelta struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+
+	Pods           CountDelta  `json:"pods"`
+	CPURequests    CPUDelta    `json:"cpuRequests"`
+	CPULimits      CPUDelta    `json:"cpuLimits"`
+	CPUUsages      CPUDelta    `json:"cpuUsage"`
+	MemoryRequests MemoryDelta `json:"memoryRequests"`
+	MemoryLimits   MemoryDelta `json:"memoryLimits"`
+	MemoryUsages   MemoryDelta `json:"memoryUsage"`
+}
+
+// SnapshotDiff is the structured output of the diff command.
+type SnapshotDiff struct {
+	Before time.Time `json:"before"`
+	After  time.Time `json:"after"`
+
+	Nodes      []ResourceDelta `json:"nodes"`
+	Namespaces []ResourceDelta `json:"namespaces"`
+}
+
+// snapshotResources are the resources of a node or namespace compared by DiffSnapshots
+type snapshotResources struct {
+	pods                                       int
+	cpuRequests, cpuLimits, cpuUsages          *CpuResource
+	memoryRequests, memoryLimits, memoryUsages *MemoryResource
+}
+
+// GetSnapshot returns the resources of all nodes and of the active pods of all namespaces. Without usage,
+// only the core API is used and the usage is unknown.
+func (k *KubeClient) GetSnapshot(ctx context.Context, usage bool) (*Snapshot, error) {
+	snapshot := &Snapshot{Version: SnapshotVersion, Time: time.Now().UTC(), Usage: usage}
+
+	var err error
+	if usage {
+		snapshot.Nodes, err = k.GetNodeResources(ctx, "", labels.Everything())
+	} else {
+		snapshot.Nodes, err = k.GetNodeResourcesWithoutUsage(ctx, "", labels.Everything())
+	}
+	if err != nil {
+		return nil, err
+	}
+	SortNodes(snapshot.Nodes, SortByName, false)
+
+	snapshot.Pods, err = k.ListPodResources(ctx, "", "", true, labels.Everything(), fields.Everything(), nil, usage)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// SaveSnapshot writes snapshot to file as indented json
+func SaveSnapshot(file string, snapshot *Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot, refusing the versions it does not know
+func LoadSnapshot(file string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("error reading snapshot file %s: %v", file, err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported version %d of snapshot file %s, expected %d", snapshot.Version, file, SnapshotVersion)
+	}
+	return snapshot, nil
+}
+
+// DiffSnapshots compares the requests, limits, usage and pod counts of every node and namespace of two
+// snapshots. Nodes and namespaces are ordered by name, the pods of a node are the ones scheduled on it.
+func DiffSnapshots(before, after *Snapshot) SnapshotDiff {
+	return SnapshotDiff{
+		Before:     before.Time,
+		After:      after.Time,
+		Nodes:      diffResources(nodeSnapshotResources(before), nodeSnapshotResources(after)),
+		Namespaces: diffResources(namespaceSnapshotResources(before), namespaceSnapshotResources(after)),
+	}
+}
+
+//nodeSnapshotResources returns the resources of the nodes of a snapshot by node name
+func nodeSnapshotResources(s *Snapshot) map[string]snapshotResources {
+	resources := map[string]snapshotResources{}
+	for _, node := range s.Nodes {
+		resources[node.Name] = snapshotResources{
+			pods:           node.AllocatedPods,
+			cpuRequests:    node.CPURequests,
+			cpuLimits:      node.CPULimits,
+			cpuUsages:      node.CPUUsages,
+			memoryRequests: node.MemoryRequests,
+			memoryLimits:   node.MemoryLimits,
+			memoryUsages:   node.MemoryUsages,
+		}
+	}
+	return resources
+}
+
+//namespaceSnapshotResources returns the resources of the pods of a snapshot summed up by namespace
+func namespaceSnapshotResources(s *Snapshot) map[string]snapshotResources {
+	resources := map[string]snapshotResources{}
+	for _, namespace := range NamespaceResources(s.Pods, "") {
+		resources[namespace.Name] = snapshotResources{
+			pods:           namespace.Pods,
+			cpuRequests:    namespace.CPURequests,
+			cpuLimits:      namespace.CPULimits,
+			cpuUsages:      namespace.CPUUsages,
+			memoryRequests: namespace.MemoryRequests,
+			memoryLimits:   namespace.MemoryLimits,
+			memoryUsages:   namespace.MemoryUsages,
+		}
+	}
+	return resources
+}
+
+//diffResources pairs the resources of before and after by name
+func diffResources(before, after map[string]snapshotResources) []ResourceDelta {
+	names := map[string]bool{}
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+
+	var deltas []ResourceDelta
+	for name := range names {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		delta := ResourceDelta{
+			Name:           name,
+			Pods:           CountDelta{Before: b.pods, After: a.pods},
+			CPURequests:    CPUDelta{Before: b.cpuRequests, After: a.cpuRequests},
+			CPULimits:      CPUDelta{Before: b.cpuLimits, After: a.cpuLimits},
+			CPUUsages:      CPUDelta{Before: b.cpuUsages, After: a.cpuUsages},
+			MemoryRequests: MemoryDelta{Before: b.memoryRequests, After: a.memoryRequests},
+			MemoryLimits:   MemoryDelta{Before: b.memoryLimits, After: a.memoryLimits},
+			MemoryUsages:   MemoryDelta{Before: b.memoryUsages, After: a.memoryUsages},
+		}
+		switch {
+		case !inBefore:
+			delta.Status = DiffAdded
+		case !inAfter:
+			delta.Status = DiffRemoved
+		case delta.changed():
+			delta.Status = DiffChanged
+		default:
+			delta.Status = DiffUnchanged
+		}
+		deltas = append(deltas, delta)
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].Name < deltas[j].Name
+	})
+	return deltas
+}
+
+//changed reports whether the pod count, a request or a limit differs, the usage is left out
+func (d ResourceDelta) changed() bool {
+	return d.Pods.Before != d.Pods.After ||
+		d.CPURequests.Changed() || d.CPULimits.Changed() ||
+		d.MemoryRequests.Changed() || d.MemoryLimits.Changed()
+}
+
+// Changed reports whether the cpu is known in both snapshots and differs
+func (d CPUDelta) Changed() bool {
+	return d.Before != nil && d.After != nil && d.Before.MilliValue() != d.After.MilliValue()
+}
+
+// Changed reports whether the memory is known in both snapshots and differs
+func (d MemoryDelta) Changed() bool {
+	return d.Before != nil && d.After != nil && d.Before.Value() != d.After.Value()
+}
@@ -0,0 +1,165 @@
+package kube
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetSnapshot(t *testing.T) {
+	c := newFakeCluster(2)
+	// a node without metrics, e.g. just joined, is kept with an unknown usage
+	if err := c.apiClient.Tracker().Add(fakeNode("node-new", "2", "4Gi")); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := c.client().GetSnapshot(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.Usage || snapshot.Version != SnapshotVersion {
+		t.Errorf("got usage %v and version %d, want true and %d", snapshot.Usage, snapshot.Version, SnapshotVersion)
+	}
+	var names []string
+	for _, node := range snapshot.Nodes {
+		names = append(names, node.Name)
+	}
+	if got := strings.Join(names, " "); got != "node-0 node-1 node-new" {
+		t.Fatalf("got nodes %s, want node-0 node-1 node-new", got)
+	}
+	if node := snapshot.Nodes[0]; node.CPUUsages.MilliValue() != 1000 || node.AllocatedPods != podsPerNode {
+		t.Errorf("got node-0 using %s with %d pods, want 1000m with %d pods", node.CPUUsages, node.AllocatedPods, podsPerNode)
+	}
+	if node := snapshot.Nodes[2]; node.CPUUsages != nil || node.MemoryUsages != nil || node.CPUCapacity.MilliValue() != 2000 {
+		t.Errorf("got node-new using %s and %s of %s, want n/a of 2000m", node.CPUUsages, node.MemoryUsages, node.CPUCapacity)
+	}
+	if len(snapshot.Pods) != 2*podsPerNode {
+		t.Errorf("got %d pods, want %d", len(snapshot.Pods), 2*podsPerNode)
+	}
+
+	// without usage the metrics API is not called
+	c.clearActions()
+	snapshot, err = c.client().GetSnapshot(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for call := range c.calls() {
+		if strings.HasPrefix(call, "metrics ") {
+			t.Errorf("got the API call %s without usage", call)
+		}
+	}
+	if snapshot.Usage || snapshot.Nodes[0].CPUUsages != nil || snapshot.Pods[0].CPUUsages != nil {
+		t.Errorf("got a snapshot with usage, want the usage unknown")
+	}
+}
+
+func TestSaveLoadSnapshot(t *testing.T) {
+	c := newFakeCluster(1)
+	snapshot, err := c.client().GetSnapshot(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := SaveSnapshot(file, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSnapshot(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !loaded.Time.Equal(snapshot.Time) || loaded.Usage != snapshot.Usage || len(loaded.Nodes) != 1 || len(loaded.Pods) != podsPerNode {
+		t.Fatalf("got %+v, want %+v", loaded, snapshot)
+	}
+	// a snapshot compared to its saved copy is unchanged
+	for _, delta := range append(DiffSnapshots(snapshot, loaded).Nodes, DiffSnapshots(snapshot, loaded).Namespaces...) {
+		if delta.Status != DiffUnchanged {
+			t.Errorf("got %s %s after loading, want unchanged", delta.Name, delta.Status)
+		}
+	}
+	if node := loaded.Nodes[0]; node.CPUUsages.MilliValue() != 1000 || node.MemoryUsages.Value() != 2<<30 || node.CPURequests.MilliValue() != 1000 {
+		t.Errorf("got node using %s and %s requesting %s, want 1000m and 2048Mi requesting 1000m", node.CPUUsages, node.MemoryUsages, node.CPURequests)
+	}
+
+	if err := ioutil.WriteFile(file, []byte(`{"version": 2, "nodes": [], "pods": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot(file); err == nil || !strings.Contains(err.Error(), "unsupported version 2") {
+		t.Errorf("got error %v, want the version 2 refused", err)
+	}
+}
+
+//testNode returns a node of a snapshot requesting cpu millicores and memory bytes, using cpuUsage and memoryUsage
+func testNode(name string, pods int, cpu, memory int64, cpuUsage *CpuResource, memoryUsage *MemoryResource) NodeResource {
+	node := NodeResource{Name: name}
+	node.AllocatedPods = pods
+	node.CPURequests, node.CPULimits, node.CPUUsages = NewCpuResource(cpu), NewCpuResource(cpu), cpuUsage
+	node.MemoryRequests, node.MemoryLimits, node.MemoryUsages = NewMemoryResource(memory), NewMemoryResource(memory), memoryUsage
+	return node
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	before := &Snapshot{
+		Time: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Nodes: []NodeResource{
+			testNode("changed", 3, 1000, 1<<30, NewCpuResource(500), NewMemoryResource(512<<20)),
+			testNode("removed", 1, 100, 1<<20, nil, nil),
+			testNode("same", 2, 200, 2<<20, NewCpuResource(100), NewMemoryResource(1<<20)),
+			testNode("usage", 2, 200, 2<<20, NewCpuResource(100), NewMemoryResource(1<<20)),
+			testNode("unknown", 2, 200, 2<<20, NewCpuResource(100), nil),
+		},
+	}
+	after := &Snapshot{
+		Time: before.Time.Add(time.Hour),
+		Nodes: []NodeResource{
+			testNode("added", 1, 100, 1<<20, nil, nil),
+			testNode("changed", 4, 1500, 1<<30, NewCpuResource(500), NewMemoryResource(512<<20)),
+			testNode("same", 2, 200, 2<<20, NewCpuResource(100), NewMemoryResource(1<<20)),
+			testNode("usage", 2, 200, 2<<20, NewCpuResource(100), NewMemoryResource(3<<20)),
+			// an unknown usage is not compared
+			testNode("unknown", 2, 200, 2<<20, nil, NewMemoryResource(1<<20)),
+		},
+	}
+
+	diff := DiffSnapshots(before, after)
+	if !diff.Before.Equal(before.Time) || !diff.After.Equal(after.Time) {
+		t.Errorf("got times %s and %s, want %s and %s", diff.Before, diff.After, before.Time, after.Time)
+	}
+	want := map[string]string{
+		"added":   DiffAdded,
+		"changed": DiffChanged,
+		"removed": DiffRemoved,
+		"same":    DiffUnchanged,
+		// the usage alone does not change a node
+		"usage":   DiffUnchanged,
+		"unknown": DiffUnchanged,
+	}
+	var names []string
+	for _, delta := range diff.Nodes {
+		names = append(names, delta.Name)
+		if delta.Status != want[delta.Name] {
+			t.Errorf("got %s %s, want %s", delta.Name, delta.Status, want[delta.Name])
+		}
+	}
+	if got := strings.Join(names, " "); got != "added changed removed same unknown usage" {
+		t.Errorf("got nodes %s, want them sorted by name", got)
+	}
+
+	changed := diff.Nodes[1]
+	if changed.Pods.Before != 3 || changed.Pods.After != 4 || changed.CPURequests.Before.MilliValue() != 1000 || changed.CPURequests.After.MilliValue() != 1500 {
+		t.Errorf("got %d to %d pods requesting %s to %s, want 3 to 4 pods requesting 1000m to 1500m",
+			changed.Pods.Before, changed.Pods.After, changed.CPURequests.Before, changed.CPURequests.After)
+	}
+	if changed.MemoryRequests.Changed() || changed.CPUUsages.Changed() {
+		t.Errorf("got the memory requests or the cpu usage of changed changed, want them unchanged")
+	}
+	if usage := diff.Nodes[5]; !usage.MemoryUsages.Changed() {
+		t.Errorf("got the memory usage of usage unchanged, want it changed from 1Mi to 3Mi")
+	}
+	if removed := diff.Nodes[2]; removed.CPURequests.After != nil || removed.Pods.After != 0 {
+		t.Errorf("got removed requesting %s after, want n/a", removed.CPURequests.After)
+	}
+}
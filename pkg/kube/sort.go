@@ -0,0 +1,171 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fields nodes and pods can be sorted by. The numeric fields are sorted from the largest value,
+// name and namespace alphabetically. An unknown usage always comes last, also in reverse.
+const (
+	SortByName      = "name"
+	SortByNamespace = "namespace"
+
+	// SortByCPU and SortByMemory are the usage.
+	SortByCPU    = "cpu"
+	SortByMemory = "memory"
+
+	// SortByCPUUsage and SortByMemUsage are the usage percentage of the limit of pods.
+	SortByCPUUsage = "cpu-usage"
+	SortByMemUsage = "mem-usage"
+
+	// SortByCPURequests, SortByCPULimits, SortByMemRequests and SortByMemLimits are the percentage
+	// of the capacity of nodes and the requests and limits of pods.
+	SortByCPURequests = "cpu-req"
+	SortByCPULimits   = "cpu-limit"
+	SortByMemRequests = "mem-req"
+	SortByMemLimits   = "mem-limit"
+
+	// SortByGPU is the number of nvidia.com/gpu requested.
+	SortByGPU = "gpu"
+	// SortByGPURequests and SortByGPULimits are the percentage of the nvidia.com/gpu capacity of nodes.
+	SortByGPURequests = "gpu-req"
+	SortByGPULimits   = "gpu-limit"
+
+	// SortByPods is the percentage of the pod capacity of nodes.
+	SortByPods = "pod"
+)
+
+// nodeSorters maps the fields nodes can be sorted by to whether a node comes before another one.
+var nodeSorters = map[string]func(a, b NodeResource) bool{
+	SortByName:        func(a, b NodeResource) bool { return a.Name < b.Name },
+	SortByCPU:         func(a, b NodeResource) bool { return a.CPUUsages.MilliValue() > b.CPUUsages.MilliValue() },
+	SortByCPURequests: func(a, b NodeResource) bool { return a.CPURequestsFraction > b.CPURequestsFraction },
+	SortByCPULimits:   func(a, b NodeResource) bool { return a.CPULimitsFraction > b.CPULimitsFraction },
+	SortByMemory:      func(a, b NodeResource) bool { return a.MemoryUsages.Value() > b.MemoryUsages.Value() },
+	SortByMemRequests: func(a, b NodeResource) bool { return a.MemoryRequestsFraction > b.MemoryRequestsFraction },
+	SortByMemLimits:   func(a, b NodeResource) bool { return a.MemoryLimitsFraction > b.MemoryLimitsFraction },
+	SortByGPU:         func(a, b NodeResource) bool { return a.NvidiaGpuCountsRequests > b.NvidiaGpuCountsRequests },
+	SortByGPURequests: func(a, b NodeResource) bool {
+		return a.NvidiaGpuCountsRequestsFraction > b.NvidiaGpuCountsRequestsFraction
+	},
+	SortByGPULimits: func(a, b NodeResource) bool {
+		return a.NvidiaGpuCountsLimitsFraction > b.NvidiaGpuCountsLimitsFraction
+	},
+	SortByPods: func(a, b NodeResource) bool { return a.PodFraction > b.PodFraction },
+}
+
+// podSorters maps the fields pods can be sorted by to whether a pod comes before another one.
+var podSorters = map[string]func(a, b PodResource) bool{
+	SortByName: func(a, b PodResource) bool { return a.Name < b.Name },
+	SortByNamespace: func(a, b PodResource) bool {
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	},
+	SortByCPU:         func(a, b PodResource) bool { return a.CPUUsages.MilliValue() > b.CPUUsages.MilliValue() },
+	SortByCPUUsage:    func(a, b PodResource) bool { return a.CPUUsagesFraction > b.CPUUsagesFraction },
+	SortByCPURequests: func(a, b PodResource) bool { return a.CPURequests.MilliValue() > b.CPURequests.MilliValue() },
+	SortByCPULimits:   func(a, b PodResource) bool { return a.CPULimits.MilliValue() > b.CPULimits.MilliValue() },
+	SortByMemory:      func(a, b PodResource) bool { return a.MemoryUsages.Value() > b.MemoryUsages.Value() },
+	SortByMemUsage:    func(a, b PodResource) bool { return a.MemoryUsagesFraction > b.MemoryUsagesFraction },
+	SortByMemRequests: func(a, b PodResource) bool { return a.MemoryRequests.Value() > b.MemoryRequests.Value() },
+	SortByMemLimits:   func(a, b PodResource) bool { return a.MemoryLimits.Value() > b.MemoryLimits.Value() },
+	SortByGPU:         func(a, b PodResource) bool { return a.NvidiaGpuCountsRequests > b.NvidiaGpuCountsRequests },
+}
+
+// nodeUsageUnknown maps the usage fields nodes can be sorted by to whether the usage of a node is unknown.
+var nodeUsageUnknown = map[string]func(r NodeResource) bool{
+	SortByCPU:    func(r NodeResource) bool { return r.CPUUsages == nil },
+	SortByMemory: func(r NodeResource) bool { return r.MemoryUsages == nil },
+}
+
+// podUsageUnknown maps the usage fields pods can be sorted by to whether the usage of a pod is unknown.
+var podUsageUnknown = map[string]func(r PodResource) bool{
+	SortByCPU:      func(r PodResource) bool { return r.CPUUsages == nil },
+	SortByCPUUsage: func(r PodResource) bool { return r.CPUUsages == nil },
+	SortByMemory:   func(r PodResource) bool { return r.MemoryUsages == nil },
+	SortByMemUsage: func(r PodResource) bool { return r.MemoryUsages == nil },
+}
+
+// NodeSortFields lists the fields nodes can be sorted by.
+var NodeSortFields = []string{
+	SortByName, SortByCPU, SortByCPURequests, SortByCPULimits, SortByMemory, SortByMemRequests, SortByMemLimits,
+	SortByGPU, SortByGPURequests, SortByGPULimits, SortByPods,
+}
+
+// PodSortFields lists the fields pods can be sorted by.
+var PodSortFields = []string{
+	SortByNamespace, SortByName, SortByCPU, SortByCPUUsage, SortByCPURequests, SortByCPULimits,
+	SortByMemory, SortByMemUsage, SortByMemRequests, SortByMemLimits, SortByGPU,
+}
+
+// ValidateNodeSortField checks that nodes can be sorted by field.
+func ValidateNodeSortField(field string) error {
+	if _, ok := nodeSorters[field]; !ok {
+		return fmt.Errorf("--sort-by accepts only %s", strings.Join(NodeSortFields, ","))
+	}
+	return nil
+}
+
+// ValidatePodSortField checks that pods can be sorted by field.
+func ValidatePodSortField(field string) error {
+	if _, ok := podSorters[field]; !ok {
+		return fmt.Errorf("--sort-by accepts only %s", strings.Join(PodSortFields, ","))
+	}
+	return nil
+}
+
+// SortNodes sorts nodes in place by field, keeping the order of equal nodes. reverse inverts the order
+// but for the nodes of unknown usage, which stay last. An empty field keeps the order of the nodes unless
+// reverse is set, which then sorts by name.
+func SortNodes(nodes []NodeResource, field string, reverse bool) {
+	if len(field) == 0 {
+		if !reverse {
+			return
+		}
+		field = SortByName
+	}
+	before, ok := nodeSorters[field]
+	if !ok {
+		return
+	}
+	unknown, usage := nodeUsageUnknown[field]
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if usage && unknown(nodes[i]) != unknown(nodes[j]) {
+			return unknown(nodes[j])
+		}
+		if reverse {
+			return before(nodes[j], nodes[i])
+		}
+		return before(nodes[i], nodes[j])
+	})
+}
+
+// SortPods sorts pods in place by field, keeping the order of equal pods. reverse inverts the order
+// but for the pods of unknown usage, which stay last. An empty field keeps the order of the pods unless
+// reverse is set, which then sorts by namespace.
+func SortPods(pods []PodResource, field string, reverse bool) {
+	if len(field) == 0 {
+		if !reverse {
+			return
+		}
+		field = SortByNamespace
+	}
+	before, ok := podSorters[field]
+	if !ok {
+		return
+	}
+	unknown, usage := podUsageUnknown[field]
+	sort.SliceStable(pods, func(i, j int) bool {
+		if usage && unknown(pods[i]) != unknown(pods[j]) {
+			return unknown(pods[j])
+		}
+		if reverse {
+			return before(pods[j], pods[i])
+		}
+		return before(pods[i], pods[j])
+	})
+}
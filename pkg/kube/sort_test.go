@@ -0,0 +1,105 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+)
+
+//sortNode returns a node using cpu millicores, unknown if cpu is negative, and requesting pct percent of its cpu
+func sortNode(name string, cpu int64, pct float64) NodeResource {
+	node := NodeResource{Name: name}
+	if cpu >= 0 {
+		node.CPUUsages = NewCpuResource(cpu)
+	}
+	node.CPURequestsFraction = pct
+	return node
+}
+
+func TestSortNodes(t *testing.T) {
+	tests := []struct {
+		field   string
+		reverse bool
+		want    string
+	}{
+		{"", false, "b c a d e"},
+		{"", true, "e d c b a"},
+		{SortByName, false, "a b c d e"},
+		{SortByName, true, "e d c b a"},
+		// equal nodes keep their order
+		{SortByCPURequests, false, "c a d b e"},
+		{SortByCPURequests, true, "b e a d c"},
+		// the unknown usage comes last, also in reverse
+		{SortByCPU, false, "c a b d e"},
+		{SortByCPU, true, "b a c d e"},
+		{SortByMemory, false, "b c a d e"},
+		{"unknown", false, "b c a d e"},
+		{"unknown", true, "b c a d e"},
+	}
+	for _, test := range tests {
+		nodes := []NodeResource{
+			sortNode("b", 100, 10),
+			sortNode("c", 300, 50),
+			sortNode("a", 200, 30),
+			sortNode("d", -1, 30),
+			sortNode("e", -1, 10),
+		}
+		SortNodes(nodes, test.field, test.reverse)
+		var names []string
+		for _, node := range nodes {
+			names = append(names, node.Name)
+		}
+		if got := strings.Join(names, " "); got != test.want {
+			t.Errorf("%q reverse %v: got %s, want %s", test.field, test.reverse, got, test.want)
+		}
+	}
+}
+
+//sortPod returns a pod using memory bytes at pct percent of its limit, unknown if memory is negative
+func sortPod(namespace, name string, memory int64, pct float64) PodResource {
+	pod := PodResource{Namespace: namespace, Name: name}
+	if memory >= 0 {
+		pod.MemoryUsages = NewMemoryResource(memory)
+		pod.MemoryUsagesFraction = pct
+	}
+	pod.MemoryRequests = NewMemoryResource(1 << 20)
+	return pod
+}
+
+func TestSortPods(t *testing.T) {
+	tests := []struct {
+		field   string
+		reverse bool
+		want    string
+	}{
+		{"", false, "b/x a/y a/x b/new"},
+		{"", true, "b/x b/new a/y a/x"},
+		{SortByNamespace, false, "a/x a/y b/new b/x"},
+		{SortByName, false, "b/new b/x a/x a/y"},
+		// equal pods keep their order
+		{SortByMemRequests, false, "b/x a/y a/x b/new"},
+		{SortByMemRequests, true, "b/x a/y a/x b/new"},
+		// the unknown usage comes last, also in reverse
+		{SortByMemory, false, "a/x b/x a/y b/new"},
+		{SortByMemory, true, "a/y b/x a/x b/new"},
+		{SortByMemUsage, false, "b/x a/x a/y b/new"},
+		{SortByMemUsage, true, "a/y a/x b/x b/new"},
+		{SortByCPU, true, "b/x a/y a/x b/new"},
+		{"unknown", true, "b/x a/y a/x b/new"},
+	}
+	for _, test := range tests {
+		pods := []PodResource{
+			sortPod("b", "x", 2<<20, 90),
+			sortPod("a", "y", 1<<20, 20),
+			sortPod("a", "x", 3<<20, 50),
+			sortPod("b", "new", -1, 0),
+		}
+		SortPods(pods, test.field, test.reverse)
+		var names []string
+		for _, pod := range pods {
+			names = append(names, pod.Namespace+"/"+pod.Name)
+		}
+		if got := strings.Join(names, " "); got != test.want {
+			t.Errorf("%q reverse %v: got %s, want %s", test.field, test.reverse, got, test.want)
+		}
+	}
+}
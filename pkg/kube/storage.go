@@ -0,0 +1,262 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// StorageResources describes the allocation of a resource of a node measured in bytes,
+// the ephemeral-storage or the hugepages of all sizes.
+type StorageResources struct {
+	// Usages is the number of used bytes from the kubelet summary API, nil if not available.
+	Usages *MemoryResource `json:"usage"`
+
+	// Requests is the sum of the requests of the pods of the node.
+	Requests *MemoryResource `json:"requests"`
+
+	// RequestsFraction is a fraction of Capacity, that is requested.
+	RequestsFraction float64 `json:"requestsFraction"`
+
+	// Limits is the sum of the limits of the pods of the node.
+	Limits *MemoryResource `json:"limits"`
+
+	// LimitsFraction is a fraction of Capacity, that is limited, can be over 100%, i.e. overcommitted.
+	LimitsFraction float64 `json:"limitsFraction"`
+
+	// Capacity is the allocatable bytes of the node.
+	Capacity *MemoryResource `json:"capacity"`
+}
+
+// PodStorageResources describes the allocation of a resource of a pod or container measured in bytes.
+type PodStorageResources struct {
+	// Usages is the number of used bytes from the kubelet summary API, nil if not available.
+	Usages *MemoryResource `json:"usage"`
+
+	// UsagesFraction is a fraction of Limits, that is used. A pod is evicted when its ephemeral-storage
+	// usage exceeds its limit.
+	UsagesFraction float64 `json:"usageFraction"`
+
+	Requests *MemoryResource `json:"requests"`
+	Limits   *MemoryResource `json:"limits"`
+}
+
+// summaryConcurrency is the number of kubelet summaries fetched at the same time
+const summaryConcurrency = 10
+
+// summary is the part of the kubelet summary API the ephemeral-storage usage is read from,
+// see k8s.io/kubelet/pkg/apis/stats/v1alpha1.
+type summary struct {
+	Node struct {
+		Fs *fsStats `json:"fs"`
+	} `json:"node"`
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		EphemeralStorage *fsStats `json:"ephemeral-storage"`
+	} `json:"pods"`
+}
+
+// fsStats is the usage of a filesystem in the kubelet summary API.
+type fsStats struct {
+	UsedBytes *int64 `json:"usedBytes"`
+}
+
+//isHugePagesResourceName reports whether name is a hugepages resource of any page size
+func isHugePagesResourceName(name v1.ResourceName) bool {
+	return strings.HasPrefix(string(name), v1.ResourceHugePagesPrefix)
+}
+
+//isEphemeralStorageResourceName reports whether name is the ephemeral-storage resource
+func isEphemeralStorageResourceName(name v1.ResourceName) bool {
+	return name == v1.ResourceEphemeralStorage
+}
+
+//sumResourceList returns the sum in bytes of the resources of list matching match
+func sumResourceList(list v1.ResourceList, match func(v1.ResourceName) bool) int64 {
+	var sum int64
+	for name, quantity := range list {
+		if match(name) {
+			sum += quantity.Value()
+		}
+	}
+	return sum
+}
+
+//getStorageResources returns the allocation of the resources of a node matching match, summed up in bytes
+func getStorageResources(reqs, limits, capacity v1.ResourceList, match func(v1.ResourceName) bool) StorageResources {
+	requests, limit, allocatable := sumResourceList(reqs, match), sumResourceList(limits, match), sumResourceList(capacity, match)
+	return StorageResources{
+		Requests:         NewMemoryResource(requests),
+		RequestsFraction: calcPercentage(requests, allocatable),
+		Limits:           NewMemoryResource(limit),
+		LimitsFraction:   calcPercentage(limit, allocatable),
+		Capacity:         NewMemoryResource(allocatable),
+	}
+}
+
+//getPodStorageResources returns the allocation of the resources of a pod or container matching match, summed up in bytes
+func getPodStorageResources(reqs, limits v1.ResourceList, match func(v1.ResourceName) bool) PodStorageResources {
+	return PodStorageResources{
+		Requests: NewMemoryResource(sumResourceList(reqs, match)),
+		Limits:   NewMemoryResource(sumResourceList(limits, match)),
+	}
+}
+
+//newStorageResources returns zero valued storage resources of a node that can be added to
+func newStorageResources() StorageResources {
+	return StorageResources{
+		Usages:   NewMemoryResource(0),
+		Requests: NewMemoryResource(0),
+		Limits:   NewMemoryResource(0),
+		Capacity: NewMemoryResource(0),
+	}
+}
+
+//addUsages sums two usages which may be unknown, the sum is unknown if one of them is, see addCPUUsages
+func addUsages(a, b *MemoryResource) *MemoryResource {
+	if a == nil || b == nil {
+		return nil
+	}
+	return NewMemoryResource(a.Value() + b.Value())
+}
+
+//addStorageResources sums the storage resources of nodes and recalculates the fractions against the summed capacity
+func addStorageResources(a, b StorageResources) StorageResources {
+	requests := a.Requests.Value() + b.Requests.Value()
+	limits := a.Limits.Value() + b.Limits.Value()
+	capacity := a.Capacity.Value() + b.Capacity.Value()
+	return StorageResources{
+		Usages:           addUsages(a.Usages, b.Usages),
+		Requests:         NewMemoryResource(requests),
+		RequestsFraction: calcPercentage(requests, capacity),
+		Limits:           NewMemoryResource(limits),
+		LimitsFraction:   calcPercentage(limits, capacity),
+		Capacity:         NewMemoryResource(capacity),
+	}
+}
+
+//addPodStorageResources sums the storage resources of pods and recalculates the usage fraction against the summed limits
+func addPodStorageResources(a, b PodStorageResources) PodStorageResources {
+	sum := PodStorageResources{
+		Usages:   addUsages(a.Usages, b.Usages),
+		Requests: NewMemoryResource(a.Requests.Value() + b.Requests.Value()),
+		Limits:   NewMemoryResource(a.Limits.Value() + b.Limits.Value()),
+	}
+	if sum.Usages != nil {
+		sum.UsagesFraction = sum.Usages.calcPercentage(sum.Limits.Quantity)
+	}
+	return sum
+}
+
+//dividePodStorageResources divides the storage resources of pods by n, keeping the usage fraction
+func dividePodStorageResources(r PodStorageResources, n int64) PodStorageResources {
+	divided := PodStorageResources{
+		UsagesFraction: r.UsagesFraction,
+		Requests:       NewMemoryResource(r.Requests.Value() / n),
+		Limits:         NewMemoryResource(r.Limits.Value() / n),
+	}
+	if r.Usages != nil {
+		divided.Usages = NewMemoryResource(r.Usages.Value() / n)
+	}
+	return divided
+}
+
+// SetNodeStorageUsage sets the ephemeral-storage usage of nodes to the used bytes of their root filesystem
+// from the kubelet summary API. The usage of the nodes whose summary cannot be fetched, e.g. without
+// the permission to get nodes/proxy, is left unknown.
+func (k *KubeClient) SetNodeStorageUsage(ctx context.Context, nodes []NodeResource) error {
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	summaries, err := k.getSummaries(ctx, names)
+	if err != nil {
+		return err
+	}
+	for i := range nodes {
+		s, ok := summaries[nodes[i].Name]
+		if !ok || s.Node.Fs == nil || s.Node.Fs.UsedBytes == nil {
+			continue
+		}
+		nodes[i].EphemeralStorage.Usages = NewMemoryResource(*s.Node.Fs.UsedBytes)
+	}
+	return nil
+}
+
+// SetPodStorageUsage sets the ephemeral-storage usage of pods from the kubelet summary API of their nodes.
+// The usage of the pods whose summary cannot be fetched is left unknown.
+func (k *KubeClient) SetPodStorageUsage(ctx context.Context, pods []PodResource) error {
+	var names []string
+	seen := map[string]bool{}
+	for _, pod := range pods {
+		if len(pod.NodeName) > 0 && !seen[pod.NodeName] {
+			seen[pod.NodeName] = true
+			names = append(names, pod.NodeName)
+		}
+	}
+	summaries, err := k.getSummaries(ctx, names)
+	if err != nil {
+		return err
+	}
+
+	usages := map[string]int64{}
+	for _, s := range summaries {
+		for _, p := range s.Pods {
+			if p.EphemeralStorage != nil && p.EphemeralStorage.UsedBytes != nil {
+				usages[p.PodRef.Namespace+"/"+p.PodRef.Name] = *p.EphemeralStorage.UsedBytes
+			}
+		}
+	}
+	for i := range pods {
+		used, ok := usages[pods[i].Namespace+"/"+pods[i].Name]
+		if !ok {
+			continue
+		}
+		storage := &pods[i].EphemeralStorage
+		storage.Usages = NewMemoryResource(used)
+		storage.UsagesFraction = storage.Usages.calcPercentage(storage.Limits.Quantity)
+	}
+	return nil
+}
+
+//getSummaries fetches the kubelet summary of the given nodes through the API server proxy, leaving out
+//the nodes whose summary cannot be fetched. Only the expiry of ctx is returned as an error.
+func (k *KubeClient) getSummaries(ctx context.Context, nodes []string) (map[string]*summary, error) {
+	summaries := map[string]*summary{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, summaryConcurrency)
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			data, err := k.apiClient.CoreV1().RESTClient().Get().
+				Resource("nodes").Name(node).SubResource("proxy").Suffix("stats", "summary").
+				DoRaw(ctx)
+			if err != nil {
+				return
+			}
+			s := &summary{}
+			if err := json.Unmarshal(data, s); err != nil {
+				return
+			}
+			mu.Lock()
+			summaries[node] = s
+			mu.Unlock()
+		}(node)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
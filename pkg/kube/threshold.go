@@ -0,0 +1,131 @@
+package kube
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Names of the percentage columns a threshold can be set for.
+const (
+	ThresholdDefault     = "default"
+	ThresholdCPURequests = "cpu-req"
+	ThresholdCPULimits   = "cpu-limit"
+	ThresholdCPUUsage    = "cpu-usage"
+	ThresholdMemRequests = "mem-req"
+	ThresholdMemLimits   = "mem-limit"
+	ThresholdMemUsage    = "mem-usage"
+	ThresholdGPURequests = "gpu-req"
+	ThresholdGPULimits   = "gpu-limit"
+	ThresholdPods        = "pod"
+)
+
+// ThresholdColumns lists the columns a threshold can be set for.
+var ThresholdColumns = []string{
+	ThresholdCPURequests, ThresholdCPULimits, ThresholdCPUUsage,
+	ThresholdMemRequests, ThresholdMemLimits, ThresholdMemUsage,
+	ThresholdGPURequests, ThresholdGPULimits, ThresholdPods,
+}
+
+// Level is the severity of a percentage compared to its threshold.
+type Level int
+
+const (
+	LevelOK Level = iota
+	LevelWarning
+	LevelCritical
+)
+
+// Threshold is the percentages above which a value is a warning or critical.
+type Threshold struct {
+	Warning  float64 `json:"warning"`
+	Critical float64 `json:"critical"`
+}
+
+// Thresholds is the default threshold of all percentage columns and the thresholds
+// overriding it for single columns.
+type Thresholds struct {
+	Default Threshold
+	Columns map[string]Threshold
+}
+
+// DefaultThresholds marks percentages above 90 as warning and above 95 as critical.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		Default: Threshold{Warning: 90, Critical: 95},
+		Columns: map[string]Threshold{},
+	}
+}
+
+// For returns the threshold of the column.
+func (t Thresholds) For(column string) Threshold {
+	if threshold, ok := t.Columns[column]; ok {
+		return threshold
+	}
+	return t.Default
+}
+
+// Level returns the severity of the percentage of the column.
+func (t Thresholds) Level(column string, percentage float64) Level {
+	threshold := t.For(column)
+	if percentage > threshold.Critical {
+		return LevelCritical
+	} else if percentage > threshold.Warning {
+		return LevelWarning
+	}
+	return LevelOK
+}
+
+// LoadThresholds reads the thresholds of a yaml or json file mapping column names,
+// or default, to a warning and a critical percentage, e.g.
+//
+//   default:   {warning: 90, critical: 95}
+//   cpu-req:   {warning: 80, critical: 90}
+//   mem-limit: {warning: 150, critical: 200}
+//
+// The thresholds of the file override the ones of t.
+func LoadThresholds(file string, t Thresholds) (Thresholds, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return t, err
+	}
+	var columns map[string]Threshold
+	if err := yaml.UnmarshalStrict(data, &columns); err != nil {
+		return t, fmt.Errorf("error reading thresholds file %s: %v", file, err)
+	}
+
+	loaded := Thresholds{Default: t.Default, Columns: map[string]Threshold{}}
+	for column, threshold := range t.Columns {
+		loaded.Columns[column] = threshold
+	}
+	for column, threshold := range columns {
+		if column == ThresholdDefault {
+			loaded.Default = threshold
+			continue
+		}
+		if !isThresholdColumn(column) {
+			return t, fmt.Errorf("unknown column %q in thresholds file %s, expected one of default, %v", column, file, ThresholdColumns)
+		}
+		loaded.Columns[column] = threshold
+	}
+	return loaded, loaded.Validate()
+}
+
+// Validate checks that no warning threshold is above its critical threshold.
+func (t Thresholds) Validate() error {
+	if t.Default.Warning > t.Default.Critical {
+		return fmt.Errorf("warning threshold %v is above critical threshold %v", t.Default.Warning, t.Default.Critical)
+	}
+	for column, threshold := range t.Columns {
+		if threshold.Warning > threshold.Critical {
+			return fmt.Errorf("warning threshold %v of %s is above its critical threshold %v", threshold.Warning, column, threshold.Critical)
+		}
+	}
+	return nil
+}
+
+//isThresholdColumn
+func isThresholdColumn(column string) bool {
+	return contains(ThresholdColumns, column)
+}
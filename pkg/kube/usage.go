@@ -0,0 +1,88 @@
+package kube
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics"
+	metricsV1beta1api "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// UsageProvider provides the cpu and memory usage of nodes and pods in the form of the metrics API.
+// A named node or pod without usage is reported as a not found error, like the metrics API does.
+type UsageProvider interface {
+	// NodeMetrics returns the usage of the node named resourceName, or of the nodes matching selector.
+	NodeMetrics(ctx context.Context, resourceName string, selector labels.Selector) (*metricsapi.NodeMetricsList, error)
+
+	// PodMetrics returns the usage of the pod named resourceName, or of the pods matching the selectors,
+	// in namespace or in all namespaces.
+	PodMetrics(ctx context.Context, namespace, resourceName string, allNamespaces bool, labelSelector labels.Selector, fieldSelector fields.Selector) (*metricsapi.PodMetricsList, error)
+}
+
+// metricsAPIUsage reads the usage from the metrics.k8s.io API, a single sample of metrics-server.
+type metricsAPIUsage struct {
+	metricsClient metrics.Interface
+}
+
+//NodeMetrics
+func (u *metricsAPIUsage) NodeMetrics(ctx context.Context, resourceName string, selector labels.Selector) (*metricsapi.NodeMetricsList, error) {
+	var err error
+	versionedMetrics := &metricsV1beta1api.NodeMetricsList{}
+	nm := u.metricsClient.MetricsV1beta1().NodeMetricses()
+
+	if resourceName != "" {
+		m, err := nm.Get(ctx, resourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		versionedMetrics.Items = []metricsV1beta1api.NodeMetrics{*m}
+	} else {
+		versionedMetrics, err = nm.List(ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	metrics := &metricsapi.NodeMetricsList{}
+	err = metricsV1beta1api.Convert_v1beta1_NodeMetricsList_To_metrics_NodeMetricsList(versionedMetrics, metrics, nil)
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+//PodMetrics
+func (u *metricsAPIUsage) PodMetrics(ctx context.Context, namespace, resourceName string, allNamespaces bool, labelSelector labels.Selector, fieldSelector fields.Selector) (*metricsapi.PodMetricsList, error) {
+	var err error
+	ns := metav1.NamespaceAll
+	if !allNamespaces {
+		ns = namespace
+	}
+	versionedMetrics := &metricsV1beta1api.PodMetricsList{}
+	if resourceName != "" {
+		m, err := u.metricsClient.MetricsV1beta1().PodMetricses(ns).Get(ctx, resourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		versionedMetrics.Items = []metricsV1beta1api.PodMetrics{*m}
+	} else {
+		versionedMetrics, err = u.metricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{
+			LabelSelector: labelSelector.String(),
+			FieldSelector: fieldSelector.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	metrics := &metricsapi.PodMetricsList{}
+	err = metricsV1beta1api.Convert_v1beta1_PodMetricsList_To_metrics_PodMetricsList(versionedMetrics, metrics, nil)
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
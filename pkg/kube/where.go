@@ -0,0 +1,495 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// fieldKind is the kind of value of a field of a filter, it tells how the values compared to it are parsed.
+type fieldKind int
+
+const (
+	// kindNumber is a count or a percentage, e.g. 80 or 80%.
+	kindNumber fieldKind = iota
+	// kindCPU is a cpu quantity in millicores, e.g. 500m or 2.
+	kindCPU
+	// kindMemory is a memory quantity in bytes, e.g. 512Mi or 2G.
+	kindMemory
+	// kindString only supports == and !=.
+	kindString
+)
+
+// nodeFields are the fields of the node filters, percentages end with .pct.
+// The usage percentages of nodes are of the capacity.
+var nodeFields = map[string]fieldKind{
+	"name":             kindString,
+	"cpu.usage":        kindCPU,
+	"cpu.usage.pct":    kindNumber,
+	"cpu.requests":     kindCPU,
+	"cpu.requests.pct": kindNumber,
+	"cpu.limits":       kindCPU,
+	"cpu.limits.pct":   kindNumber,
+	"cpu.capacity":     kindCPU,
+	"mem.usage":        kindMemory,
+	"mem.usage.pct":    kindNumber,
+	"mem.requests":     kindMemory,
+	"mem.requests.pct": kindNumber,
+	"mem.limits":       kindMemory,
+	"mem.limits.pct":   kindNumber,
+	"mem.capacity":     kindMemory,
+	"gpu.requests":     kindNumber,
+	"gpu.requests.pct": kindNumber,
+	"gpu.limits":       kindNumber,
+	"gpu.limits.pct":   kindNumber,
+	"gpu.capacity":     kindNumber,
+	"pods":             kindNumber,
+	"pods.pct":         kindNumber,
+	"pods.capacity":    kindNumber,
+}
+
+// podFields are the fields of the pod filters, percentages end with .pct.
+// The usage percentages of pods are of the limits.
+var podFields = map[string]fieldKind{
+	"namespace":     kindString,
+	"name":          kindString,
+	"node":          kindString,
+	"cpu.usage":     kindCPU,
+	"cpu.usage.pct": kindNumber,
+	"cpu.requests":  kindCPU,
+	"cpu.limits":    kindCPU,
+	"mem.usage":     kindMemory,
+	"mem.usage.pct": kindNumber,
+	"mem.requests":  kindMemory,
+	"mem.limits":    kindMemory,
+	"gpu.requests":  kindNumber,
+	"gpu.limits":    kindNumber,
+}
+
+// Filter is a parsed filter expression on the computed values of nodes or pods, e.g.
+//
+//   cpu.requests.pct>80 && mem.usage.pct<20
+//   (mem.limits.pct>=150 || !(pods.pct<90)) && name!=master-1
+//
+// Comparisons are one of >, >=, <, <=, == and !=, combined with &&, || and !, && binding tighter than ||.
+// Cpu and memory values are quantities, e.g. 500m or 2Gi, strings may be quoted. As NULL in SQL, a
+// comparison of an unknown usage is neither true nor false, and only the nodes or pods for which the
+// whole expression is true match.
+type Filter struct {
+	expr whereExpr
+}
+
+// record is the value of every field of a node or a pod, float64 or string. An unknown usage has no value.
+type record map[string]interface{}
+
+// truth is the value of a filter expression, ordered so that && is the minimum and || the maximum.
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthUnknown
+	truthTrue
+)
+
+// whereExpr is a node of the syntax tree of a filter.
+type whereExpr interface {
+	eval(r record) truth
+}
+
+type andExpr struct{ left, right whereExpr }
+
+type orExpr struct{ left, right whereExpr }
+
+type notExpr struct{ expr whereExpr }
+
+// comparison compares a field to a number, or to a string for the string fields.
+type comparison struct {
+	field string
+	op    string
+	num   float64
+	str   string
+}
+
+func (e andExpr) eval(r record) truth {
+	left, right := e.left.eval(r), e.right.eval(r)
+	if left < right {
+		return left
+	}
+	return right
+}
+
+func (e orExpr) eval(r record) truth {
+	left, right := e.left.eval(r), e.right.eval(r)
+	if left > right {
+		return left
+	}
+	return right
+}
+
+func (e notExpr) eval(r record) truth { return truthTrue - e.expr.eval(r) }
+
+func (e comparison) eval(r record) truth {
+	switch v := r[e.field].(type) {
+	case string:
+		if e.op == "==" {
+			return toTruth(v == e.str)
+		}
+		return toTruth(v != e.str)
+	case float64:
+		switch e.op {
+		case ">":
+			return toTruth(v > e.num)
+		case ">=":
+			return toTruth(v >= e.num)
+		case "<":
+			return toTruth(v < e.num)
+		case "<=":
+			return toTruth(v <= e.num)
+		case "==":
+			return toTruth(v == e.num)
+		case "!=":
+			return toTruth(v != e.num)
+		}
+	}
+	return truthUnknown
+}
+
+//toTruth
+func toTruth(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+// ParseNodeFilter parses a filter on the fields of nodes.
+func ParseNodeFilter(s string) (*Filter, error) {
+	return parseFilter(s, nodeFields)
+}
+
+// ParsePodFilter parses a filter on the fields of pods.
+func ParsePodFilter(s string) (*Filter, error) {
+	return parseFilter(s, podFields)
+}
+
+// NodeFilterFields lists the fields of the node filters.
+func NodeFilterFields() []string {
+	return filterFields(nodeFields)
+}
+
+// PodFilterFields lists the fields of the pod filters.
+func PodFilterFields() []string {
+	return filterFields(podFields)
+}
+
+// Nodes returns the nodes matching the filter, all of them for a nil filter.
+func (f *Filter) Nodes(nodes []NodeResource) []NodeResource {
+	if f == nil {
+		return nodes
+	}
+	var matched []NodeResource
+	for _, r := range nodes {
+		if f.expr.eval(nodeRecord(r)) == truthTrue {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Pods returns the pods matching the filter, all of them for a nil filter.
+func (f *Filter) Pods(pods []PodResource) []PodResource {
+	if f == nil {
+		return pods
+	}
+	var matched []PodResource
+	for _, r := range pods {
+		if f.expr.eval(podRecord(r)) == truthTrue {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+//nodeRecord
+func nodeRecord(r NodeResource) record {
+	rec := record{
+		"name":             r.Name,
+		"cpu.requests":     milliValue(r.CPURequests),
+		"cpu.requests.pct": r.CPURequestsFraction,
+		"cpu.limits":       milliValue(r.CPULimits),
+		"cpu.limits.pct":   r.CPULimitsFraction,
+		"cpu.capacity":     milliValue(r.CPUCapacity),
+		"mem.requests":     byteValue(r.MemoryRequests),
+		"mem.requests.pct": r.MemoryRequestsFraction,
+		"mem.limits":       byteValue(r.MemoryLimits),
+		"mem.limits.pct":   r.MemoryLimitsFraction,
+		"mem.capacity":     byteValue(r.MemoryCapacity),
+		"gpu.requests":     float64(r.NvidiaGpuCountsRequests),
+		"gpu.requests.pct": r.NvidiaGpuCountsRequestsFraction,
+		"gpu.limits":       float64(r.NvidiaGpuCountsLimits),
+		"gpu.limits.pct":   r.NvidiaGpuCountsLimitsFraction,
+		"gpu.capacity":     float64(r.NvidiaGpuCountsCapacity),
+		"pods":             float64(r.AllocatedPods),
+		"pods.pct":         r.PodFraction,
+		"pods.capacity":    float64(r.PodCapacity),
+	}
+	if r.CPUUsages != nil {
+		rec["cpu.usage"] = milliValue(r.CPUUsages)
+		rec["cpu.usage.pct"] = calcPercentage(int64(milliValue(r.CPUUsages)), int64(milliValue(r.CPUCapacity)))
+	}
+	if r.MemoryUsages != nil {
+		rec["mem.usage"] = byteValue(r.MemoryUsages)
+		rec["mem.usage.pct"] = calcPercentage(int64(byteValue(r.MemoryUsages)), int64(byteValue(r.MemoryCapacity)))
+	}
+	return rec
+}
+
+//podRecord
+func podRecord(r PodResource) record {
+	rec := record{
+		"namespace":    r.Namespace,
+		"name":         r.Name,
+		"node":         r.NodeName,
+		"cpu.requests": milliValue(r.CPURequests),
+		"cpu.limits":   milliValue(r.CPULimits),
+		"mem.requests": byteValue(r.MemoryRequests),
+		"mem.limits":   byteValue(r.MemoryLimits),
+		"gpu.requests": float64(r.NvidiaGpuCountsRequests),
+		"gpu.limits":   float64(r.NvidiaGpuCountsLimits),
+	}
+	if r.CPUUsages != nil {
+		rec["cpu.usage"] = milliValue(r.CPUUsages)
+		rec["cpu.usage.pct"] = r.CPUUsagesFraction
+	}
+	if r.MemoryUsages != nil {
+		rec["mem.usage"] = byteValue(r.MemoryUsages)
+		rec["mem.usage.pct"] = r.MemoryUsagesFraction
+	}
+	return rec
+}
+
+//milliValue returns the millicores of r, 0 when unset
+func milliValue(r *CpuResource) float64 {
+	if r == nil || r.Quantity == nil {
+		return 0
+	}
+	return float64(r.MilliValue())
+}
+
+//byteValue returns the bytes of r, 0 when unset
+func byteValue(r *MemoryResource) float64 {
+	if r == nil || r.Quantity == nil {
+		return 0
+	}
+	return float64(r.Value())
+}
+
+//filterFields
+func filterFields(fields map[string]fieldKind) []string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filterParser is a recursive descent parser of filter expressions.
+type filterParser struct {
+	tokens []string
+	pos    int
+	fields map[string]fieldKind
+}
+
+//parseFilter
+func parseFilter(s string, fields map[string]fieldKind) (*Filter, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+	p := &filterParser{tokens: tokens, fields: fields}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in filter expression %q", p.tokens[p.pos], s)
+	}
+	return &Filter{expr: expr}, nil
+}
+
+//peek returns the current token, empty at the end
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+//next consumes the current token
+func (p *filterParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+//parseOr
+func (p *filterParser) parseOr() (whereExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+//parseAnd
+func (p *filterParser) parseAnd() (whereExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+//parseUnary parses a negation, a parenthesized expression or a comparison
+func (p *filterParser) parseUnary() (whereExpr, error) {
+	switch p.peek() {
+	case "!":
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	case "(":
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ) in filter expression")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+//parseComparison parses field op value, checking the value against the kind of the field
+func (p *filterParser) parseComparison() (whereExpr, error) {
+	field := p.next()
+	if len(field) == 0 {
+		return nil, fmt.Errorf("unexpected end of filter expression")
+	}
+	field = strings.Replace(field, "memory.", "mem.", 1)
+	kind, ok := p.fields[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q in filter expression, expected one of %s", field, strings.Join(filterFields(p.fields), ","))
+	}
+	op := p.next()
+	if op == "=" {
+		op = "=="
+	}
+	switch op {
+	case ">", ">=", "<", "<=", "==", "!=":
+	default:
+		return nil, fmt.Errorf("expected a comparison after %s in filter expression, got %q", field, op)
+	}
+	value := p.next()
+	if len(value) == 0 || isOperator(value) {
+		return nil, fmt.Errorf("expected a value after %s%s in filter expression", field, op)
+	}
+
+	c := comparison{field: field, op: op}
+	switch kind {
+	case kindString:
+		if op != "==" && op != "!=" {
+			return nil, fmt.Errorf("%s only supports == and != in filter expression", field)
+		}
+		c.str = strings.Trim(value, `"'`)
+	case kindCPU, kindMemory:
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for %s in filter expression", value, field)
+		}
+		if kind == kindCPU {
+			c.num = float64(q.MilliValue())
+		} else {
+			c.num = float64(q.Value())
+		}
+	default:
+		num, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q for %s in filter expression", value, field)
+		}
+		c.num = num
+	}
+	return c, nil
+}
+
+//isOperator
+func isOperator(token string) bool {
+	switch token {
+	case "&&", "||", "!", "(", ")", ">", ">=", "<", "<=", "==", "!=", "=":
+		return true
+	}
+	return false
+}
+
+//tokenize splits a filter expression into operators, quoted strings and words
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in filter expression %q", s)
+			}
+			tokens = append(tokens, s[i:i+end+2])
+			i += end + 2
+		case strings.HasPrefix(s[i:], "&&") || strings.HasPrefix(s[i:], "||") ||
+			strings.HasPrefix(s[i:], ">=") || strings.HasPrefix(s[i:], "<=") ||
+			strings.HasPrefix(s[i:], "==") || strings.HasPrefix(s[i:], "!="):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.IndexByte("()!<>=", c) >= 0:
+			tokens = append(tokens, s[i:i+1])
+			i++
+		case strings.IndexByte("&|", c) >= 0:
+			return nil, fmt.Errorf("unexpected %q in filter expression %q, expected && or ||", c, s)
+		default:
+			start := i
+			for i < len(s) && strings.IndexByte(" \t\"'()!<>=&|", s[i]) < 0 {
+				i++
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,134 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+)
+
+// whereNode returns a node named name using cpu millicores of 4 cores and memory bytes of 8Gi, a nil usage
+// being unknown, requesting half of the capacity
+func whereNode(name string, cpu *CpuResource, memory *MemoryResource) NodeResource {
+	node := testNode(name, 10, 2000, 4<<30, cpu, memory)
+	node.CPUCapacity, node.MemoryCapacity = NewCpuResource(4000), NewMemoryResource(8<<30)
+	node.CPURequestsFraction, node.MemoryRequestsFraction = 50, 50
+	node.PodCapacity, node.PodFraction = 110, 9.09
+	return node
+}
+
+// matchedNames returns the names of the nodes matched by expr
+func matchedNames(t *testing.T, expr string, nodes []NodeResource) string {
+	t.Helper()
+	f, err := ParseNodeFilter(expr)
+	if err != nil {
+		t.Fatalf("%s: %v", expr, err)
+	}
+	var names []string
+	for _, node := range f.Nodes(nodes) {
+		names = append(names, node.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestFilterNodes(t *testing.T) {
+	nodes := []NodeResource{
+		whereNode("idle", NewCpuResource(100), NewMemoryResource(1<<30)),
+		whereNode("busy", NewCpuResource(3600), NewMemoryResource(6<<30)),
+		whereNode("new", nil, nil),
+	}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"cpu.usage>500m", "busy"},
+		{"cpu.usage>=3.6", "busy"},
+		{"cpu.usage.pct<10", "idle"},
+		{"mem.requests>=2Gi", "idle busy new"},
+		{"mem.usage<2Gi", "idle"},
+		// memory. is an alias of mem.
+		{"memory.usage<2Gi", "idle"},
+		// a trailing % is allowed on the numbers
+		{"cpu.usage.pct>80%", "busy"},
+		{"pods.pct<10%", "idle busy new"},
+		{"name==busy", "busy"},
+		{"name='busy'", "busy"},
+		{`name!="busy"`, "idle new"},
+		// && binds tighter than ||
+		{"name==idle || name==busy && cpu.usage<1", "idle"},
+		{"(name==idle || name==busy) && cpu.usage<1", "idle"},
+		{"(name==idle || name==busy) && cpu.usage>1", "busy"},
+		{"name==new || name==busy && cpu.usage>1", "busy new"},
+		{"!(name==idle)", "busy new"},
+		{"!name==idle && !name==busy", "new"},
+		// a comparison of an unknown usage is neither true nor false
+		{"cpu.usage>1", "busy"},
+		{"!(cpu.usage>1)", "idle"},
+		{"cpu.usage>1 || !(cpu.usage>1)", "idle busy"},
+		{"cpu.usage>1 || name==new", "busy new"},
+		{"!(cpu.usage>1 && name==new)", "idle busy"},
+		{"mem.usage.pct>=0", "idle busy"},
+	}
+	for _, test := range tests {
+		if got := matchedNames(t, test.expr, nodes); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestFilterPods(t *testing.T) {
+	pod := PodResource{Namespace: "kube-system", Name: "dns", NodeName: "node-0"}
+	pod.CPUUsages, pod.CPUUsagesFraction = NewCpuResource(250), 50
+	pod.MemoryRequests = NewMemoryResource(64 << 20)
+	pending := PodResource{Namespace: "default", Name: "pending"}
+
+	for _, test := range []struct {
+		expr string
+		want int
+	}{
+		{"namespace==kube-system && cpu.usage.pct>=50", 1},
+		{"cpu.usage<=250m", 1},
+		{"mem.requests>32Mi", 1},
+		{"node!=node-0", 1},
+		{"mem.usage>0 || !(mem.usage>0)", 0},
+	} {
+		f, err := ParsePodFilter(test.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", test.expr, err)
+		}
+		if got := f.Pods([]PodResource{pod, pending}); len(got) != test.want {
+			t.Errorf("%s: got %d pods, want %d", test.expr, len(got), test.want)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"cpu.usage>1 & name==a", `unexpected '&' in filter expression "cpu.usage>1 & name==a", expected && or ||`},
+		{"cpu.usage>1 | name==a", `unexpected '|'`},
+		{`name=="busy`, "unterminated string in filter expression"},
+		{"disk.usage>1", `unknown field "disk.usage" in filter expression, expected one of cpu.capacity,`},
+		{"name<b", "name only supports == and != in filter expression"},
+		{"name>=b", "name only supports == and != in filter expression"},
+		{"cpu.usage>lots", `invalid quantity "lots" for cpu.usage`},
+		{"pods.pct>high", `invalid number "high" for pods.pct`},
+		{"cpu.usage 1", `expected a comparison after cpu.usage in filter expression, got "1"`},
+		{"cpu.usage>", "expected a value after cpu.usage> in filter expression"},
+		{"(cpu.usage>1", "missing ) in filter expression"},
+		{"cpu.usage>1)", `unexpected ")" in filter expression`},
+		{"cpu.usage>1 &&", "unexpected end of filter expression"},
+		{"  ", "empty filter expression"},
+	}
+	for _, test := range tests {
+		_, err := ParseNodeFilter(test.expr)
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: got error %v, want %q", test.expr, err, test.wantErr)
+		}
+	}
+
+	// the pods have no capacity
+	if _, err := ParsePodFilter("cpu.capacity>1"); err == nil || !strings.Contains(err.Error(), `unknown field "cpu.capacity"`) {
+		t.Errorf("got error %v, want cpu.capacity unknown for pods", err)
+	}
+}
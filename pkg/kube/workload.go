@@ -0,0 +1,193 @@
+package kube
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/pager"
+)
+
+// WorkloadResource is the sum of the allocated resources of all pods owned by a workload.
+type WorkloadResource struct {
+	Namespace string `json:"namespace"`
+
+	// Kind is the kind of the top level owner of the pods, i.e. Deployment, StatefulSet,
+	// DaemonSet, CronJob, Job, or Pod for pods without a controller.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	// Replicas is the number of pods summed up in the workload.
+	Replicas int `json:"replicas"`
+
+	PodAllocatedResources
+
+	// PerReplica is the average of the allocated resources of a single pod.
+	PerReplica PodAllocatedResources `json:"perReplica"`
+}
+
+// WorkloadResourceList is the structured output of the workload command.
+type WorkloadResourceList struct {
+	Items []WorkloadResource `json:"items"`
+}
+
+// workloadKey identifies a workload by namespace, kind and name.
+type workloadKey struct {
+	namespace string
+	kind      string
+	name      string
+}
+
+// GetWorkloadResources groups pod resources by the workload owning the pods, resolving
+// ReplicaSets to their Deployment and Jobs to their CronJob. Workloads are ordered by
+// namespace, kind and name, or by descending usage when sortBy is cpu or memory, an unknown usage last.
+func (k *KubeClient) GetWorkloadResources(ctx context.Context, podresources []PodResource, namespace string, allNamespaces bool, sortBy string) ([]WorkloadResource, error) {
+	owners, err := k.getWorkloadOwners(ctx, namespace, allNamespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[workloadKey]int)
+	var workloads []WorkloadResource
+	for _, podresource := range podresources {
+		key := podWorkload(podresource, owners)
+		i, ok := index[key]
+		if !ok {
+			i = len(workloads)
+			index[key] = i
+			workloads = append(workloads, WorkloadResource{
+				Namespace:             key.namespace,
+				Kind:                  key.kind,
+				Name:                  key.name,
+				PodAllocatedResources: newPodAllocatedResources(),
+			})
+		}
+		workloads[i].Replicas++
+		workloads[i].PodAllocatedResources = addPodAllocatedResources(workloads[i].PodAllocatedResources, podresource.PodAllocatedResources)
+	}
+
+	for i := range workloads {
+		workloads[i].PerReplica = dividePodAllocatedResources(workloads[i].PodAllocatedResources, workloads[i].Replicas)
+	}
+
+	sort.SliceStable(workloads, func(i, j int) bool {
+		switch sortBy {
+		case "cpu":
+			a, b := workloads[i].CPUUsages, workloads[j].CPUUsages
+			if (a == nil) != (b == nil) {
+				return b == nil
+			}
+			return a.MilliValue() > b.MilliValue()
+		case "memory":
+			a, b := workloads[i].MemoryUsages, workloads[j].MemoryUsages
+			if (a == nil) != (b == nil) {
+				return b == nil
+			}
+			return a.Value() > b.Value()
+		default:
+			if workloads[i].Namespace != workloads[j].Namespace {
+				return workloads[i].Namespace < workloads[j].Namespace
+			}
+			if workloads[i].Kind != workloads[j].Kind {
+				return workloads[i].Kind < workloads[j].Kind
+			}
+			return workloads[i].Name < workloads[j].Name
+		}
+	})
+	return workloads, nil
+}
+
+// getWorkloadOwners maps the kind/namespace/name of every ReplicaSet and Job to the controller owning it,
+// listing them in pages of listPageSize.
+func (k *KubeClient) getWorkloadOwners(ctx context.Context, namespace string, allNamespaces bool) (map[string]*metav1.OwnerReference, error) {
+	ns := metav1.NamespaceAll
+	if !allNamespaces {
+		ns = namespace
+	}
+
+	owners := make(map[string]*metav1.OwnerReference)
+	err := listControllers(ctx, "ReplicaSet", owners, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return k.apiClient.AppsV1().ReplicaSets(ns).List(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = listControllers(ctx, "Job", owners, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return k.apiClient.BatchV1().Jobs(ns).List(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return owners, nil
+}
+
+//listControllers maps the kind/namespace/name of every object listed by list, in pages of listPageSize,
+//to the controller owning it
+func listControllers(ctx context.Context, kind string, owners map[string]*metav1.OwnerReference, list pager.ListPageFunc) error {
+	p := pager.New(list)
+	p.PageSize = listPageSize
+	return p.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		owners[kind+"/"+o.GetNamespace()+"/"+o.GetName()] = metav1.GetControllerOf(o)
+		return nil
+	})
+}
+
+// podWorkload returns the top level workload of a pod from its controller.
+func podWorkload(podresource PodResource, owners map[string]*metav1.OwnerReference) workloadKey {
+	ref := podresource.Controller
+	if ref == nil {
+		return workloadKey{podresource.Namespace, "Pod", podresource.Name}
+	}
+	switch ref.Kind {
+	case "ReplicaSet", "Job":
+		if owner := owners[ref.Kind+"/"+podresource.Namespace+"/"+ref.Name]; owner != nil {
+			return workloadKey{podresource.Namespace, owner.Kind, owner.Name}
+		}
+	}
+	return workloadKey{podresource.Namespace, ref.Kind, ref.Name}
+}
+
+// dividePodAllocatedResources returns r divided by n, keeping the fractions of r and an unknown usage.
+func dividePodAllocatedResources(r PodAllocatedResources, n int) PodAllocatedResources {
+	if n == 0 {
+		return r
+	}
+	d := int64(n)
+	var cpuUsages *CpuResource
+	if r.CPUUsages != nil {
+		cpuUsages = NewCpuResource(r.CPUUsages.MilliValue() / d)
+	}
+	var memoryUsages *MemoryResource
+	if r.MemoryUsages != nil {
+		memoryUsages = NewMemoryResource(r.MemoryUsages.Value() / d)
+	}
+	return PodAllocatedResources{
+		PodCPUResources{
+			CPUUsages:         cpuUsages,
+			CPUUsagesFraction: r.CPUUsagesFraction,
+			CPURequests:       NewCpuResource(r.CPURequests.MilliValue() / d),
+			CPULimits:         NewCpuResource(r.CPULimits.MilliValue() / d),
+		},
+		PodMemoryResources{
+			MemoryUsages:         memoryUsages,
+			MemoryUsagesFraction: r.MemoryUsagesFraction,
+			MemoryRequests:       NewMemoryResource(r.MemoryRequests.Value() / d),
+			MemoryLimits:         NewMemoryResource(r.MemoryLimits.Value() / d),
+		},
+		PodGPUResources{
+			NvidiaGpuCountsRequests: r.NvidiaGpuCountsRequests / d,
+			NvidiaGpuCountsLimits:   r.NvidiaGpuCountsLimits / d,
+			AliyunGpuMemRequests:    r.AliyunGpuMemRequests / d,
+			AliyunGpuMemLimits:      r.AliyunGpuMemLimits / d,
+		},
+		dividePodExtendedResources(r.ExtendedResources, d),
+		dividePodStorageResources(r.EphemeralStorage, d),
+		dividePodStorageResources(r.HugePages, d),
+	}
+}
@@ -0,0 +1,66 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+//controller returns the reference of a controller of kind and name
+func controller(kind, name string) *metav1.OwnerReference {
+	yes := true
+	return &metav1.OwnerReference{Kind: kind, Name: name, Controller: &yes}
+}
+
+func TestGetWorkloadResources(t *testing.T) {
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-5d4f", OwnerReferences: []metav1.OwnerReference{*controller("Deployment", "web")}}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup-2760", OwnerReferences: []metav1.OwnerReference{*controller("CronJob", "backup")}}}
+	// a ReplicaSet of another namespace is not listed without all namespaces
+	other := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "web-5d4f", OwnerReferences: []metav1.OwnerReference{*controller("Deployment", "other")}}}
+	c := &fakeCluster{apiClient: fake.NewSimpleClientset([]runtime.Object{rs, job, other}...), metricsClient: &metricsfake.Clientset{}}
+
+	pod := func(name string, ref *metav1.OwnerReference) PodResource {
+		r := PodResource{Namespace: "default", Name: name, Controller: ref}
+		r.PodAllocatedResources = newPodAllocatedResources()
+		return r
+	}
+	podresources := []PodResource{
+		pod("web-5d4f-a", controller("ReplicaSet", "web-5d4f")),
+		pod("web-5d4f-b", controller("ReplicaSet", "web-5d4f")),
+		pod("backup-2760-a", controller("Job", "backup-2760")),
+		pod("db-0", controller("StatefulSet", "db")),
+		// a ReplicaSet which is not found is the workload itself
+		pod("api-7c9-a", controller("ReplicaSet", "api-7c9")),
+		pod("debug", nil),
+	}
+	workloads, err := c.client().GetWorkloadResources(context.Background(), podresources, "default", false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.assertCalls(t, map[string]int{"list replicasets": 1, "list jobs": 1})
+
+	want := []struct {
+		kind, name string
+		replicas   int
+	}{
+		{"CronJob", "backup", 1},
+		{"Deployment", "web", 2},
+		{"Pod", "debug", 1},
+		{"ReplicaSet", "api-7c9", 1},
+		{"StatefulSet", "db", 1},
+	}
+	if len(workloads) != len(want) {
+		t.Fatalf("got %d workloads, want %d", len(workloads), len(want))
+	}
+	for i, w := range want {
+		if got := workloads[i]; got.Kind != w.kind || got.Name != w.name || got.Replicas != w.replicas {
+			t.Errorf("got %s/%s of %d replicas, want %s/%s of %d", got.Kind, got.Name, got.Replicas, w.kind, w.name, w.replicas)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+// column is a column a pane can be sorted by.
+type column struct {
+	name string
+	// field is the sort field of the column, see kube.SortNodes and kube.SortPods.
+	field string
+}
+
+var nodeColumns = []column{
+	{"NODE", kube.SortByName},
+	{"CPU USE", kube.SortByCPU},
+	{"CPU REQ(%)", kube.SortByCPURequests},
+	{"CPU LIM(%)", kube.SortByCPULimits},
+	{"MEM USE", kube.SortByMemory},
+	{"MEM REQ(%)", kube.SortByMemRequests},
+	{"MEM LIM(%)", kube.SortByMemLimits},
+	{"NVIDIA/GPU REQ(%)", kube.SortByGPURequests},
+	{"POD(%)", kube.SortByPods},
+}
+
+var podColumns = []column{
+	{"NAMESPACE", kube.SortByNamespace},
+	{"POD NAME", kube.SortByName},
+	{"CPU USE", kube.SortByCPU},
+	{"CPU USE(%)", kube.SortByCPUUsage},
+	{"CPU REQ", kube.SortByCPURequests},
+	{"CPU LIM", kube.SortByCPULimits},
+	{"MEM USE", kube.SortByMemory},
+	{"MEM USE(%)", kube.SortByMemUsage},
+	{"MEM REQ", kube.SortByMemRequests},
+	{"MEM LIM", kube.SortByMemLimits},
+	{"NVIDIA/GPU REQ", kube.SortByGPU},
+}
+
+//sortNodes sorts a copy of nodes by the given column
+func sortNodes(nodes []kube.NodeResource, column int, reverse bool) []kube.NodeResource {
+	sorted := append([]kube.NodeResource(nil), nodes...)
+	kube.SortNodes(sorted, nodeColumns[column].field, reverse)
+	return sorted
+}
+
+//sortPods sorts a copy of pods by the given column
+func sortPods(pods []kube.PodResource, column int, reverse bool) []kube.PodResource {
+	sorted := append([]kube.PodResource(nil), pods...)
+	kube.SortPods(sorted, podColumns[column].field, reverse)
+	return sorted
+}
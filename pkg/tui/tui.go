@@ -0,0 +1,440 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+	"github.com/bryant-rh/kubectl-resource-view/pkg/writer"
+
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	paneNodes = iota
+	panePods
+)
+
+const (
+	enterScreen = "\033[?1049h\033[?25l"
+	leaveScreen = "\033[?25h\033[?1049l"
+	clearScreen = "\033[H\033[2J"
+
+	keyUp    = "\033[A"
+	keyDown  = "\033[B"
+	keyRight = "\033[C"
+	keyLeft  = "\033[D"
+	keyEsc   = "\033"
+	keyEnter = "\r"
+	keyTab   = "\t"
+	keyCtrlC = "\003"
+)
+
+// helpLine lists the key bindings of the dashboard
+const helpLine = "tab: nodes/pods  up/down: select  left/right: sort column  r: reverse  " +
+	"n: namespace  l: label selector  enter: pods of node  esc: back  q: quit"
+
+// Dashboard is a full screen view of the nodes and pods of a cluster, refreshed every Interval.
+type Dashboard struct {
+	Client       *kube.KubeClient
+	Interval     time.Duration
+	ResourceType []string
+	// NoUsage skips the metrics API, the usage is shown as n/a
+	NoUsage bool
+	// Thresholds colour the percentage columns
+	Thresholds kube.Thresholds
+
+	fd      int
+	pane    int
+	nodes   []kube.NodeResource
+	pods    []kube.PodResource
+	err     error
+	updated time.Time
+	// stale is set when the filters changed since the last fetch, fetching while a fetch is running
+	stale    bool
+	fetching bool
+
+	// selected, sortBy and reverse are kept per pane
+	selected [2]int
+	sortBy   [2]int
+	reverse  [2]bool
+
+	// namespace filters the pods, empty for all namespaces
+	namespace string
+	// selector is the label selector of the nodes and of the pods
+	selector [2]string
+	// node filters the pods to the ones scheduled on the node drilled down into
+	node string
+
+	// prompt is the name of the value being edited, empty when no prompt is open
+	prompt      string
+	promptValue string
+}
+
+//Run shows the dashboard on the terminal of in until q is pressed
+func (d *Dashboard) Run(in *os.File, out io.Writer) error {
+	d.fd = int(in.Fd())
+	if !term.IsTerminal(d.fd) {
+		return errors.New("top requires an interactive terminal")
+	}
+	state, err := term.MakeRaw(d.fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(d.fd, state)
+
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	// a pending read of stdin cannot be interrupted, the keys are read from the terminal opened again
+	// where possible so that closing it stops readKeys on return
+	keysIn := in
+	if tty, err := os.Open(in.Name()); err == nil {
+		defer tty.Close()
+		keysIn = tty
+	}
+	keys := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	go readKeys(keysIn, keys, done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := make(chan fetchResult, 1)
+
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	d.refresh(ctx, results)
+	for {
+		d.draw(out)
+		select {
+		case key, ok := <-keys:
+			if !ok || d.handleKey(key) {
+				return nil
+			}
+			if d.stale && !d.fetching {
+				d.refresh(ctx, results)
+			}
+		case result := <-results:
+			d.fetching = false
+			if d.stale {
+				// the filters changed during the fetch, its result is dropped for a fresh one
+				d.refresh(ctx, results)
+			} else {
+				d.apply(result)
+			}
+		case <-ticker.C:
+			if !d.fetching {
+				d.refresh(ctx, results)
+			}
+		}
+	}
+}
+
+//readKeys sends every key press read from in, escape sequences being a single key, until in fails or done is closed
+func readKeys(in io.Reader, keys chan<- string, done <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 32)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		select {
+		case keys <- string(buf[:n]):
+		case <-done:
+			return
+		}
+	}
+}
+
+// fetchResult is the outcome of a fetch, posted back to the key loop of Run
+type fetchResult struct {
+	nodes []kube.NodeResource
+	pods  []kube.PodResource
+	err   error
+}
+
+//refresh fetches the nodes and pods with the current filters in the background, giving up after 30 seconds,
+//and posts the result to results. Only one fetch runs at a time, results needs room for its result.
+func (d *Dashboard) refresh(ctx context.Context, results chan<- fetchResult) {
+	d.fetching, d.stale = true, false
+	namespace, selector := d.namespace, d.selector
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		results <- d.fetch(ctx, namespace, selector)
+	}()
+}
+
+//apply shows the result of a fetch, keeping the previous nodes and pods on error
+func (d *Dashboard) apply(result fetchResult) {
+	d.err = result.err
+	d.updated = time.Now()
+	if result.err == nil {
+		d.nodes, d.pods = result.nodes, result.pods
+	}
+}
+
+//fetch lists the nodes and the pods of namespace, all namespaces if empty, matching the label selectors of
+//the panes. It runs outside of the key loop and reads only the fields which never change.
+func (d *Dashboard) fetch(ctx context.Context, namespace string, selector [2]string) fetchResult {
+	nodeSelector, err := labels.Parse(selector[paneNodes])
+	if err != nil {
+		return fetchResult{err: err}
+	}
+	podSelector, err := labels.Parse(selector[panePods])
+	if err != nil {
+		return fetchResult{err: err}
+	}
+
+	var nodes []kube.NodeResource
+	if d.NoUsage {
+		nodes, err = d.Client.GetNodeResourcesWithoutUsage(ctx, "", nodeSelector)
+	} else {
+		nodes, err = d.Client.GetNodeResources(ctx, "", nodeSelector)
+	}
+	if err != nil {
+		return fetchResult{err: err}
+	}
+	allNamespaces := len(namespace) == 0
+	pods, err := d.Client.ListPodResources(ctx, namespace, "", allNamespaces, podSelector, fields.Everything(), nil, !d.NoUsage)
+	if err != nil {
+		return fetchResult{err: err}
+	}
+	return fetchResult{nodes: nodes, pods: pods}
+}
+
+//visibleNodes returns the nodes in the order shown
+func (d *Dashboard) visibleNodes() []kube.NodeResource {
+	return sortNodes(d.nodes, d.sortBy[paneNodes], d.reverse[paneNodes])
+}
+
+//visiblePods returns the pods of the node drilled down into, if any, in the order shown
+func (d *Dashboard) visiblePods() []kube.PodResource {
+	var pods []kube.PodResource
+	for _, pod := range d.pods {
+		if len(d.node) == 0 || pod.NodeName == d.node {
+			pods = append(pods, pod)
+		}
+	}
+	return sortPods(pods, d.sortBy[panePods], d.reverse[panePods])
+}
+
+//rows returns the number of rows of the current pane
+func (d *Dashboard) rows() int {
+	if d.pane == paneNodes {
+		return len(d.visibleNodes())
+	}
+	return len(d.visiblePods())
+}
+
+//handleKey applies a key press, it returns true to quit
+func (d *Dashboard) handleKey(key string) bool {
+	if len(d.prompt) > 0 {
+		d.handlePromptKey(key)
+		return false
+	}
+
+	columns := len(nodeColumns)
+	if d.pane == panePods {
+		columns = len(podColumns)
+	}
+
+	switch key {
+	case "q", keyCtrlC:
+		return true
+	case keyTab:
+		d.pane = 1 - d.pane
+	case keyUp, "k":
+		if d.selected[d.pane] > 0 {
+			d.selected[d.pane]--
+		}
+	case keyDown, "j":
+		if d.selected[d.pane] < d.rows()-1 {
+			d.selected[d.pane]++
+		}
+	case keyLeft:
+		d.sortBy[d.pane] = (d.sortBy[d.pane] + columns - 1) % columns
+	case keyRight:
+		d.sortBy[d.pane] = (d.sortBy[d.pane] + 1) % columns
+	case "r":
+		d.reverse[d.pane] = !d.reverse[d.pane]
+	case "n":
+		d.prompt, d.promptValue = "namespace", d.namespace
+	case "l":
+		d.prompt, d.promptValue = "label selector", d.selector[d.pane]
+	case keyEnter:
+		if d.pane == paneNodes {
+			nodes := d.visibleNodes()
+			if d.selected[paneNodes] < len(nodes) {
+				d.node = nodes[d.selected[paneNodes]].Name
+				d.pane = panePods
+				d.selected[panePods] = 0
+			}
+		}
+	case keyEsc:
+		if d.pane == panePods && len(d.node) > 0 {
+			d.node = ""
+			d.pane = paneNodes
+		}
+	}
+	return false
+}
+
+//handlePromptKey edits the value of the open prompt, enter applies it and esc discards it
+func (d *Dashboard) handlePromptKey(key string) {
+	switch key {
+	case keyEnter:
+		switch d.prompt {
+		case "namespace":
+			d.namespace = strings.TrimSpace(d.promptValue)
+		case "label selector":
+			if _, err := labels.Parse(d.promptValue); err != nil {
+				d.err = err
+				d.prompt = ""
+				return
+			}
+			d.selector[d.pane] = strings.TrimSpace(d.promptValue)
+		}
+		d.prompt = ""
+		d.selected[d.pane] = 0
+		d.stale = true
+	case keyEsc, keyCtrlC:
+		d.prompt = ""
+	case "\177", "\b":
+		if len(d.promptValue) > 0 {
+			d.promptValue = d.promptValue[:len(d.promptValue)-1]
+		}
+	default:
+		if strings.HasPrefix(key, keyEsc) {
+			return
+		}
+		for _, c := range key {
+			if c >= ' ' && c <= '~' {
+				d.promptValue += string(c)
+			}
+		}
+	}
+}
+
+//draw renders the current pane, scrolled to keep the selected row visible
+func (d *Dashboard) draw(out io.Writer) {
+	_, height, err := term.GetSize(d.fd)
+	if err != nil {
+		height = 24
+	}
+
+	var lines []string
+	lines = append(lines, d.statusLine(), helpLine)
+	if len(d.prompt) > 0 {
+		lines = append(lines, fmt.Sprintf("%s: %s_", d.prompt, d.promptValue))
+	} else if d.err != nil {
+		lines = append(lines, fmt.Sprintf("error: %v", d.err))
+	} else {
+		lines = append(lines, "")
+	}
+
+	// the table usually has three lines of header above the rows and one below, the rows are marked below
+	// the actual header reported by the writers
+	visible := height - len(lines) - 4
+	if visible < 1 {
+		visible = 1
+	}
+	d.clampSelection()
+	offset := 0
+	if d.selected[d.pane] >= visible {
+		offset = d.selected[d.pane] - visible + 1
+	}
+
+	var table bytes.Buffer
+	var header int
+	if d.pane == paneNodes {
+		nodes := d.visibleNodes()
+		header = writer.NodeWrite(&table, nodes[offset:min(offset+visible, len(nodes))], d.ResourceType, d.Thresholds, false)
+	} else {
+		pods := d.visiblePods()
+		header = writer.PodWrite(&table, pods[offset:min(offset+visible, len(pods))], podResourceType(d.ResourceType), d.Thresholds, false)
+	}
+	for i, line := range strings.Split(strings.TrimRight(table.String(), "\n"), "\n") {
+		if i-header == d.selected[d.pane]-offset {
+			lines = append(lines, "> "+line)
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+
+	// the terminal is in raw mode, lines have to return the carriage themselves
+	fmt.Fprint(out, clearScreen+strings.Join(lines, "\r\n"))
+}
+
+//clampSelection keeps the selected row of the current pane within its rows, which change on refresh
+func (d *Dashboard) clampSelection() {
+	if d.selected[d.pane] >= d.rows() {
+		d.selected[d.pane] = d.rows() - 1
+	}
+	if d.selected[d.pane] < 0 {
+		d.selected[d.pane] = 0
+	}
+}
+
+//statusLine describes the pane, its sort order and filters
+func (d *Dashboard) statusLine() string {
+	panes := "[Nodes]  Pods "
+	column := nodeColumns[d.sortBy[paneNodes]].name
+	if d.pane == panePods {
+		panes = " Nodes  [Pods]"
+		column = podColumns[d.sortBy[panePods]].name
+	}
+	// numeric columns are sorted from the largest value, names alphabetically
+	order := ""
+	if d.reverse[d.pane] {
+		order = " reversed"
+	}
+	namespace := d.namespace
+	if len(namespace) == 0 {
+		namespace = "all"
+	}
+	status := fmt.Sprintf("%s   sort: %s%s   namespace: %s", panes, column, order, namespace)
+	if len(d.selector[d.pane]) > 0 {
+		status += "   selector: " + d.selector[d.pane]
+	}
+	if len(d.node) > 0 {
+		status += "   node: " + d.node
+	}
+	status += "   updated: " + d.updated.Format("15:04:05")
+	if d.fetching {
+		status += " (refreshing)"
+	}
+	return status
+}
+
+//podResourceType drops the node only pod type from resourceType
+func podResourceType(resourceType []string) []string {
+	var types []string
+	for _, t := range resourceType {
+		if t != "pod" {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return []string{""}
+	}
+	return types
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
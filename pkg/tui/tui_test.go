@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+//testDashboard returns a dashboard of the nodes node-a using 300m, node-b using 100m and node-c using 200m,
+//running the pods a-1 and a-2 on node-a and b-1 on node-b, drawn 24 lines high as its fd is not a terminal
+func testDashboard() *Dashboard {
+	node := func(name string, cpu int64) kube.NodeResource {
+		r := kube.NodeResource{Name: name}
+		r.CPUUsages, r.MemoryUsages = kube.NewCpuResource(cpu), kube.NewMemoryResource(cpu<<20)
+		return r
+	}
+	pod := func(name, nodeName string) kube.PodResource {
+		return kube.PodResource{Namespace: "default", Name: name, NodeName: nodeName}
+	}
+	return &Dashboard{
+		ResourceType: []string{"cpu"},
+		Thresholds:   kube.DefaultThresholds(),
+		fd:           -1,
+		nodes:        []kube.NodeResource{node("node-a", 300), node("node-b", 100), node("node-c", 200)},
+		pods:         []kube.PodResource{pod("a-1", "node-a"), pod("b-1", "node-b"), pod("a-2", "node-a")},
+	}
+}
+
+//press applies the keys in turn, it returns whether the last one quit
+func (d *Dashboard) press(keys ...string) bool {
+	quit := false
+	for _, key := range keys {
+		quit = d.handleKey(key)
+	}
+	return quit
+}
+
+//names returns the names of the nodes or pods shown in the current pane
+func (d *Dashboard) names() string {
+	var names []string
+	if d.pane == paneNodes {
+		for _, node := range d.visibleNodes() {
+			names = append(names, node.Name)
+		}
+	} else {
+		for _, pod := range d.visiblePods() {
+			names = append(names, pod.Name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+func TestHandleKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		quit     bool
+		pane     int
+		selected int
+		sortBy   int
+		reverse  bool
+		prompt   string
+	}{
+		{name: "quit", keys: []string{"q"}, quit: true},
+		{name: "ctrl-c", keys: []string{keyCtrlC}, quit: true},
+		{name: "tab", keys: []string{keyTab}, pane: panePods},
+		{name: "tab twice", keys: []string{keyTab, keyTab}, pane: paneNodes},
+		{name: "down", keys: []string{keyDown, "j"}, selected: 2},
+		{name: "down past the last row", keys: []string{keyDown, keyDown, keyDown, keyDown}, selected: 2},
+		{name: "up past the first row", keys: []string{keyDown, keyUp, "k"}, selected: 0},
+		{name: "reverse", keys: []string{"r"}, reverse: true},
+		{name: "reverse twice", keys: []string{"r", "r"}},
+		{name: "namespace prompt", keys: []string{"n"}, prompt: "namespace"},
+		{name: "label selector prompt", keys: []string{keyTab, "l"}, pane: panePods, prompt: "label selector"},
+		// a key of an open prompt is typed, not applied
+		{name: "q in a prompt", keys: []string{"n", "q"}, prompt: "namespace"},
+		// the pods pane has its own selection
+		{name: "selection per pane", keys: []string{keyDown, keyTab, keyDown}, pane: panePods, selected: 1},
+	}
+	for _, test := range tests {
+		d := testDashboard()
+		if quit := d.press(test.keys...); quit != test.quit {
+			t.Errorf("%s: got quit %v, want %v", test.name, quit, test.quit)
+		}
+		if d.pane != test.pane || d.selected[d.pane] != test.selected || d.sortBy[d.pane] != test.sortBy ||
+			d.reverse[d.pane] != test.reverse || d.prompt != test.prompt {
+			t.Errorf("%s: got pane %d, row %d, sort %d, reverse %v and prompt %q, want %d, %d, %d, %v and %q", test.name,
+				d.pane, d.selected[d.pane], d.sortBy[d.pane], d.reverse[d.pane], d.prompt,
+				test.pane, test.selected, test.sortBy, test.reverse, test.prompt)
+		}
+	}
+}
+
+func TestSortCycling(t *testing.T) {
+	d := testDashboard()
+	if got := d.names(); got != "node-a node-b node-c" {
+		t.Errorf("got %s by name, want node-a node-b node-c", got)
+	}
+	d.press(keyRight)
+	if !strings.Contains(d.statusLine(), "sort: CPU USE ") || d.names() != "node-a node-c node-b" {
+		t.Errorf("got %s with the status %q, want node-a node-c node-b sorted by CPU USE", d.names(), d.statusLine())
+	}
+	d.press("r")
+	if !strings.Contains(d.statusLine(), "sort: CPU USE reversed") || d.names() != "node-b node-c node-a" {
+		t.Errorf("got %s with the status %q, want node-b node-c node-a reversed", d.names(), d.statusLine())
+	}
+
+	// left of the first column is the last one, right of the last column the first one
+	d = testDashboard()
+	d.press(keyLeft)
+	if d.sortBy[paneNodes] != len(nodeColumns)-1 {
+		t.Errorf("got column %d left of the first one, want %d", d.sortBy[paneNodes], len(nodeColumns)-1)
+	}
+	d.press(keyRight)
+	if d.sortBy[paneNodes] != 0 {
+		t.Errorf("got column %d right of the last one, want 0", d.sortBy[paneNodes])
+	}
+	// the pods pane cycles through its own columns
+	d.press(keyTab, keyLeft)
+	if d.sortBy[panePods] != len(podColumns)-1 || d.sortBy[paneNodes] != 0 {
+		t.Errorf("got columns %v, want the pods sorted by %d and the nodes by 0", d.sortBy, len(podColumns)-1)
+	}
+}
+
+func TestDrillDown(t *testing.T) {
+	d := testDashboard()
+	// enter on node-a, the first node by name
+	d.press(keyEnter)
+	if d.pane != panePods || d.node != "node-a" || d.names() != "a-1 a-2" {
+		t.Fatalf("got pane %d of node %q with the pods %s, want the pods a-1 a-2 of node-a", d.pane, d.node, d.names())
+	}
+	if !strings.Contains(d.statusLine(), "node: node-a") {
+		t.Errorf("got the status %q, want node-a in it", d.statusLine())
+	}
+
+	// enter on node-b once sorted by cpu usage, the pod selection starts over
+	d.press(keyDown, keyEsc, keyRight, keyDown, keyDown, keyEnter)
+	if d.pane != panePods || d.node != "node-b" || d.names() != "b-1" || d.selected[panePods] != 0 {
+		t.Fatalf("got pane %d of node %q with the pods %s at row %d, want the pod b-1 of node-b at row 0", d.pane, d.node, d.names(), d.selected[panePods])
+	}
+
+	d.press(keyEsc)
+	if d.pane != paneNodes || len(d.node) > 0 || d.selected[paneNodes] != 2 {
+		t.Errorf("got pane %d of node %q at row %d, want the nodes back at row 2", d.pane, d.node, d.selected[paneNodes])
+	}
+	// esc on the pods of all nodes and enter on the pods do nothing
+	d.press(keyTab, keyEsc, keyEnter)
+	if d.pane != panePods || len(d.node) > 0 || d.names() != "a-1 a-2 b-1" {
+		t.Errorf("got pane %d of node %q with the pods %s, want all pods", d.pane, d.node, d.names())
+	}
+}
+
+func TestHandlePromptKey(t *testing.T) {
+	d := testDashboard()
+	d.press(keyDown, "n")
+	d.press("kube-systemx", "\177", keyUp)
+	if d.promptValue != "kube-system" {
+		t.Errorf("got the prompt value %q, want kube-system", d.promptValue)
+	}
+	d.press(keyEnter)
+	if d.namespace != "kube-system" || len(d.prompt) > 0 || !d.stale || d.selected[paneNodes] != 0 {
+		t.Errorf("got namespace %q, prompt %q, stale %v and row %d, want kube-system applied at row 0", d.namespace, d.prompt, d.stale, d.selected[paneNodes])
+	}
+
+	// esc discards the value
+	d = testDashboard()
+	d.press("n", "other", keyEsc)
+	if len(d.namespace) > 0 || len(d.prompt) > 0 || d.stale {
+		t.Errorf("got namespace %q, prompt %q and stale %v, want the prompt discarded", d.namespace, d.prompt, d.stale)
+	}
+
+	// the label selector is set on the current pane only, once valid
+	d = testDashboard()
+	d.press(keyTab, "l", " app=web ", keyEnter)
+	if d.selector[panePods] != "app=web" || len(d.selector[paneNodes]) > 0 {
+		t.Errorf("got the selectors %q, want app=web for the pods only", d.selector)
+	}
+	d.press("l", "\b\b\b\b\b\b\b", "app in (", keyEnter)
+	if d.err == nil || d.selector[panePods] != "app=web" || len(d.prompt) > 0 {
+		t.Errorf("got error %v and selector %q, want an error keeping app=web", d.err, d.selector[panePods])
+	}
+}
+
+func TestClampSelection(t *testing.T) {
+	d := testDashboard()
+	d.selected[paneNodes] = 5
+	d.clampSelection()
+	if d.selected[paneNodes] != 2 {
+		t.Errorf("got row %d of 3 nodes, want 2", d.selected[paneNodes])
+	}
+
+	// the nodes went away on refresh
+	d.nodes = nil
+	d.clampSelection()
+	if d.selected[paneNodes] != 0 {
+		t.Errorf("got row %d without nodes, want 0", d.selected[paneNodes])
+	}
+}
+
+func TestDrawMarksSelected(t *testing.T) {
+	for _, resourceType := range [][]string{{"cpu"}, {"cpu", "memory", "pod"}} {
+		d := testDashboard()
+		d.ResourceType = resourceType
+		d.press(keyDown)
+		var out bytes.Buffer
+		d.draw(&out)
+
+		var marked []string
+		for _, line := range strings.Split(out.String(), "\r\n") {
+			if strings.HasPrefix(line, "> ") {
+				marked = append(marked, line)
+			}
+		}
+		if len(marked) != 1 || !strings.Contains(marked[0], "node-b") {
+			t.Errorf("%v: got the lines %q marked, want the line of node-b", resourceType, marked)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package writer
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+//NodeCSVWrite prints nodes as csv or tsv
+func NodeCSVWrite(out io.Writer, data []kube.NodeResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	if err := w.Write(csvHeader(nodeCSVHeader(resourceType, "NODE"))); err != nil {
+		return err
+	}
+	for _, i := range data {
+		row := []string{i.Name}
+		for _, t := range resourceType {
+			row = append(row, nodeValues(i.NodeAllocatedResources, t)...)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//NodeGroupCSVWrite prints the nodes of every group followed by the subtotal of the group as csv or tsv
+func NodeGroupCSVWrite(out io.Writer, data []kube.NodeGroupResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	if err := w.Write(csvHeader(nodeCSVHeader(resourceType, "GROUP", "NODE"))); err != nil {
+		return err
+	}
+	for _, g := range data {
+		for _, i := range g.Nodes {
+			row := []string{g.Value, i.Name}
+			for _, t := range resourceType {
+				row = append(row, nodeValues(i.NodeAllocatedResources, t)...)
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		row := []string{g.Value, subtotalName(g.Total.Nodes)}
+		for _, t := range resourceType {
+			row = append(row, nodeValues(g.Total.NodeAllocatedResources, t)...)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//PodCSVWrite prints pods as csv or tsv
+func PodCSVWrite(out io.Writer, data []kube.PodResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	if err := w.Write(csvHeader(podHeader(resourceType, "NAMESPACE", "POD NAME"))); err != nil {
+		return err
+	}
+	for _, i := range data {
+		row := []string{i.Namespace, i.Name}
+		for _, t := range resourceType {
+			row = append(row, podValues(i.PodAllocatedResources, t)...)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//ContainerCSVWrite prints the containers of pods as csv or tsv
+func ContainerCSVWrite(out io.Writer, data []kube.PodResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	if err := w.Write(csvHeader(podHeader(resourceType, "NAMESPACE", "POD NAME", "CONTAINER"))); err != nil {
+		return err
+	}
+	for _, i := range data {
+		for _, c := range i.Containers {
+			row := []string{i.Namespace, i.Name, containerName(c)}
+			for _, t := range resourceType {
+				row = append(row, podValues(c.PodAllocatedResources, t)...)
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//NamespaceCSVWrite prints namespaces as csv or tsv
+func NamespaceCSVWrite(out io.Writer, data []kube.NamespaceResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	if err := w.Write(csvHeader(podHeader(resourceType, "NAMESPACE", "PODS"))); err != nil {
+		return err
+	}
+	for _, i := range data {
+		row := []string{i.Name, intToString(i.Pods)}
+		for _, t := range resourceType {
+			row = append(row, podValues(i.PodAllocatedResources, t)...)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//WorkloadCSVWrite prints workloads as csv or tsv
+func WorkloadCSVWrite(out io.Writer, data []kube.WorkloadResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	header := append(podHeader(resourceType, "NAMESPACE", "KIND", "WORKLOAD", "REPLICAS"), replicaHeader(resourceType)...)
+	if err := w.Write(csvHeader(header)); err != nil {
+		return err
+	}
+	for _, i := range data {
+		row := []string{i.Namespace, i.Kind, i.Name, intToString(i.Replicas)}
+		for _, t := range resourceType {
+			row = append(row, podValues(i.PodAllocatedResources, t)...)
+		}
+		for _, t := range resourceType {
+			row = append(row, replicaValues(i.PerReplica, t)...)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//ClusterCSVWrite prints the cluster summary as csv or tsv
+func ClusterCSVWrite(out io.Writer, data kube.ClusterResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	header := append([]string{"NODES"}, clusterHeader...)
+	if err := w.Write(csvHeader(header)); err != nil {
+		return err
+	}
+	for _, t := range resourceType {
+		for _, row := range clusterValues(data, t) {
+			if err := w.Write(append([]string{intToString(data.Nodes)}, row...)); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//IsCSVOutput reports whether output is one of the delimited formats
+func IsCSVOutput(output string) bool {
+	return output == "csv" || output == "tsv"
+}
+
+//csvWriter returns the writer of the csv and tsv outputs. Their values are plain numbers, cpu in millicores,
+//memory in bytes and percentages without the % sign, an unknown usage is empty.
+func csvWriter(out io.Writer, output string) *csv.Writer {
+	w := csv.NewWriter(out)
+	if output == "tsv" {
+		w.Comma = '\t'
+	}
+	return w
+}
+
+//csvHeader formats the header the same way the table does
+func csvHeader(header []string) []string {
+	for i := range header {
+		header[i] = strings.ToUpper(strings.TrimSpace(header[i]))
+	}
+	return header
+}
+
+//nodeCSVHeader returns the header of nodeValues, the header of nodeRow with the capacity in columns of its own
+//instead of after the slash of the requests
+func nodeCSVHeader(resourceType []string, leading ...string) []string {
+	header := append([]string{}, leading...)
+	for _, t := range resourceType {
+		switch t {
+		case "cpu":
+			header = append(header,
+				"CPU USE", "CPU CAPACITY", "CPU REQ", "CPU REQ(%)", "CPU LIM", "CPU LIM(%)",
+			)
+		case "memory":
+			header = append(header,
+				"MEM USE", "MEM CAPACITY", "MEM REQ", "MEM REQ(%)", "MEM LIM", "MEM LIM(%)",
+			)
+		case "pod":
+			header = append(header,
+				"POD", "POD CAPACITY", "POD(%)",
+			)
+		case "":
+			header = append(header, nodeCSVHeader([]string{"cpu", "memory", "gpu", "pod"})...)
+		default:
+			header = append(header, nodeHeader([]string{t})...)
+		}
+	}
+	return header
+}
+
+//nodeValues returns the raw values of the columns of nodeCSVHeader
+func nodeValues(noderesource kube.NodeAllocatedResources, t string) []string {
+	switch {
+	case t == "cpu":
+		return []string{
+			milliToString(noderesource.CPUUsages), milliToString(noderesource.CPUCapacity),
+			milliToString(noderesource.CPURequests), fractionToString(noderesource.CPURequestsFraction),
+			milliToString(noderesource.CPULimits), fractionToString(noderesource.CPULimitsFraction),
+		}
+	case t == "memory":
+		return []string{
+			bytesToString(noderesource.MemoryUsages), bytesToString(noderesource.MemoryCapacity),
+			bytesToString(noderesource.MemoryRequests), fractionToString(noderesource.MemoryRequestsFraction),
+			bytesToString(noderesource.MemoryLimits), fractionToString(noderesource.MemoryLimitsFraction),
+		}
+	case t == "gpu":
+		return []string{
+			int64ToString(noderesource.NvidiaGpuCountsRequests), fractionToString(noderesource.NvidiaGpuCountsRequestsFraction),
+			int64ToString(noderesource.NvidiaGpuCountsLimits), fractionToString(noderesource.NvidiaGpuCountsLimitsFraction),
+		}
+	case t == "pod":
+		return []string{
+			intToString(noderesource.AllocatedPods), int64ToString(noderesource.PodCapacity), fractionToString(noderesource.PodFraction),
+		}
+	case t == "ephemeral-storage":
+		s := noderesource.EphemeralStorage
+		return append([]string{bytesToString(s.Usages)}, storageValues(s)...)
+	case t == "hugepages":
+		return storageValues(noderesource.HugePages)
+	case t == "":
+		var row []string
+		for _, t := range []string{"cpu", "memory", "gpu", "pod"} {
+			row = append(row, nodeValues(noderesource, t)...)
+		}
+		return row
+	default:
+		e := noderesource.ExtendedResources[t]
+		return []string{
+			int64ToString(e.Requests), fractionToString(e.RequestsFraction),
+			int64ToString(e.Limits), fractionToString(e.LimitsFraction),
+		}
+	}
+}
+
+//podValues returns the raw values of the columns of podRow
+func podValues(podresource kube.PodAllocatedResources, t string) []string {
+	switch {
+	case t == "cpu":
+		return []string{
+			milliToString(podresource.CPUUsages), usageFractionValue(podresource.CPUUsages != nil, podresource.CPUUsagesFraction),
+			milliToString(podresource.CPURequests), milliToString(podresource.CPULimits),
+		}
+	case t == "memory":
+		return []string{
+			bytesToString(podresource.MemoryUsages), usageFractionValue(podresource.MemoryUsages != nil, podresource.MemoryUsagesFraction),
+			bytesToString(podresource.MemoryRequests), bytesToString(podresource.MemoryLimits),
+		}
+	case t == "gpu":
+		return []string{
+			int64ToString(podresource.NvidiaGpuCountsRequests), int64ToString(podresource.NvidiaGpuCountsLimits),
+		}
+	case t == "ephemeral-storage":
+		s := podresource.EphemeralStorage
+		return []string{
+			bytesToString(s.Usages), usageFractionValue(s.Usages != nil, s.UsagesFraction),
+			bytesToString(s.Requests), bytesToString(s.Limits),
+		}
+	case t == "hugepages":
+		return []string{
+			bytesToString(podresource.HugePages.Requests), bytesToString(podresource.HugePages.Limits),
+		}
+	case t == "":
+		var row []string
+		for _, t := range []string{"cpu", "memory", "gpu"} {
+			row = append(row, podValues(podresource, t)...)
+		}
+		return row
+	default:
+		e := podresource.ExtendedResources[t]
+		return []string{
+			int64ToString(e.Requests), int64ToString(e.Limits),
+		}
+	}
+}
+
+//replicaValues returns the raw values of the columns of replicaRow
+func replicaValues(podresource kube.PodAllocatedResources, t string) []string {
+	switch {
+	case t == "cpu":
+		return []string{
+			milliToString(podresource.CPUUsages), milliToString(podresource.CPURequests), milliToString(podresource.CPULimits),
+		}
+	case t == "memory":
+		return []string{
+			bytesToString(podresource.MemoryUsages), bytesToString(podresource.MemoryRequests), bytesToString(podresource.MemoryLimits),
+		}
+	case t == "gpu":
+		return []string{
+			int64ToString(podresource.NvidiaGpuCountsRequests), int64ToString(podresource.NvidiaGpuCountsLimits),
+		}
+	case t == "":
+		var row []string
+		for _, t := range []string{"cpu", "memory", "gpu"} {
+			row = append(row, replicaValues(podresource, t)...)
+		}
+		return row
+	default:
+		e := podresource.ExtendedResources[t]
+		return []string{
+			int64ToString(e.Requests), int64ToString(e.Limits),
+		}
+	}
+}
+
+//clusterValues returns the raw values of the rows of clusterRow
+func clusterValues(cluster kube.ClusterResource, t string) [][]string {
+	switch {
+	case t == "cpu":
+		return [][]string{{
+			"cpu", milliToString(cluster.CPUCapacity),
+			milliToString(cluster.CPURequests), fractionToString(cluster.CPURequestsFraction),
+			milliToString(cluster.CPULimits), fractionToString(cluster.CPULimitsFraction),
+			milliToString(cluster.CPUUsages), usageFractionValue(cluster.CPUUsages != nil, cluster.CPUUsagesFraction),
+			fractionToString(cluster.CPUOvercommit),
+		}}
+	case t == "memory":
+		return [][]string{{
+			"memory", bytesToString(cluster.MemoryCapacity),
+			bytesToString(cluster.MemoryRequests), fractionToString(cluster.MemoryRequestsFraction),
+			bytesToString(cluster.MemoryLimits), fractionToString(cluster.MemoryLimitsFraction),
+			bytesToString(cluster.MemoryUsages), usageFractionValue(cluster.MemoryUsages != nil, cluster.MemoryUsagesFraction),
+			fractionToString(cluster.MemoryOvercommit),
+		}}
+	case t == "gpu":
+		return [][]string{{
+			"nvidia.com/gpu", int64ToString(cluster.NvidiaGpuCountsCapacity),
+			int64ToString(cluster.NvidiaGpuCountsRequests), fractionToString(cluster.NvidiaGpuCountsRequestsFraction),
+			int64ToString(cluster.NvidiaGpuCountsLimits), fractionToString(cluster.NvidiaGpuCountsLimitsFraction),
+			"", "", "",
+		}}
+	case t == "pod":
+		return [][]string{{
+			"pods", int64ToString(cluster.PodCapacity),
+			intToString(cluster.AllocatedPods), fractionToString(cluster.PodFraction),
+			"", "", "", "", "",
+		}}
+	case t == "":
+		var rows [][]string
+		for _, t := range []string{"cpu", "memory", "gpu", "pod"} {
+			rows = append(rows, clusterValues(cluster, t)...)
+		}
+		return rows
+	default:
+		e := cluster.ExtendedResources[t]
+		return [][]string{{
+			t, int64ToString(e.Capacity),
+			int64ToString(e.Requests), fractionToString(e.RequestsFraction),
+			int64ToString(e.Limits), fractionToString(e.LimitsFraction),
+			"", "", "",
+		}}
+	}
+}
+
+//storageValues returns the raw values of the columns of storageRow
+func storageValues(s kube.StorageResources) []string {
+	return []string{
+		bytesToString(s.Requests), fractionToString(s.RequestsFraction),
+		bytesToString(s.Limits), fractionToString(s.LimitsFraction),
+	}
+}
+
+//milliToString
+func milliToString(r *kube.CpuResource) string {
+	if r == nil {
+		return ""
+	}
+	return int64ToString(r.MilliValue())
+}
+
+//bytesToString
+func bytesToString(r *kube.MemoryResource) string {
+	if r == nil {
+		return ""
+	}
+	return int64ToString(r.Value())
+}
+
+//fractionToString
+func fractionToString(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+//usageFractionValue returns the raw fraction of a usage, empty when the usage is unknown
+func usageFractionValue(known bool, f float64) string {
+	if !known {
+		return ""
+	}
+	return fractionToString(f)
+}
@@ -0,0 +1,60 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+func TestNodeCSVWriteCapacity(t *testing.T) {
+	node := kube.NodeResource{Name: "node-a"}
+	node.CPUCapacity = kube.NewCpuResource(4000)
+	node.CPURequests = kube.NewCpuResource(1000)
+	node.MemoryCapacity = kube.NewMemoryResource(8 << 30)
+	node.MemoryRequests = kube.NewMemoryResource(2 << 30)
+	node.PodCapacity = 110
+	node.AllocatedPods = 11
+
+	tests := []struct {
+		resourceType []string
+		want         map[string]string
+	}{
+		{[]string{"cpu"}, map[string]string{"CPU CAPACITY": "4000", "CPU REQ": "1000"}},
+		{[]string{"memory"}, map[string]string{"MEM CAPACITY": "8589934592", "MEM REQ": "2147483648"}},
+		{[]string{"pod"}, map[string]string{"POD": "11", "POD CAPACITY": "110"}},
+		{[]string{""}, map[string]string{"CPU CAPACITY": "4000", "MEM CAPACITY": "8589934592", "POD CAPACITY": "110"}},
+		{[]string{"pod", "cpu", "hugepages"}, map[string]string{"POD CAPACITY": "110", "CPU CAPACITY": "4000"}},
+	}
+	for _, output := range []string{"csv", "tsv"} {
+		for _, tt := range tests {
+			var out bytes.Buffer
+			if err := NodeCSVWrite(&out, []kube.NodeResource{node}, tt.resourceType, output); err != nil {
+				t.Fatalf("%s %v: %v", output, tt.resourceType, err)
+			}
+			r := csv.NewReader(&out)
+			if output == "tsv" {
+				r.Comma = '\t'
+			}
+			records, err := r.ReadAll()
+			if err != nil {
+				t.Fatalf("%s %v: %v", output, tt.resourceType, err)
+			}
+			if len(records) != 2 || len(records[0]) != len(records[1]) {
+				t.Fatalf("%s %v: got %q, want a header and a row of the same length", output, tt.resourceType, records)
+			}
+			got := map[string]string{}
+			for i, column := range records[0] {
+				got[column] = records[1][i]
+			}
+			for column, want := range tt.want {
+				if value, ok := got[column]; ok && value != want {
+					t.Errorf("%s %v: got %s %q, want %q", output, tt.resourceType, column, value, want)
+				} else if !ok {
+					t.Errorf("%s %v: got no %s column in %q", output, tt.resourceType, column, records[0])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+// diffColumns are the columns of SnapshotDiffWrite after the name
+var diffColumns = []string{"STATUS", "PODS", "CPU REQ", "CPU LIM", "CPU USE", "MEM REQ", "MEM LIM", "MEM USE"}
+
+//SnapshotDiffWrite prints the changes of the nodes and of the namespaces between two snapshots, as the
+//value in the second snapshot followed by the difference to the first one
+func SnapshotDiffWrite(out io.Writer, diff kube.SnapshotDiff, outType bool) {
+	deltaTableWrite(out, "NODE", diff.Nodes, outType)
+	fmt.Fprintln(out)
+	deltaTableWrite(out, "NAMESPACE", diff.Namespaces, outType)
+}
+
+//deltaTableWrite prints a table of deltas whose first column is name
+func deltaTableWrite(out io.Writer, name string, data []kube.ResourceDelta, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(append([]string{name}, diffColumns...))
+	for _, i := range data {
+		table.Append([]string{
+			i.Name, diffStatusColor(i.Status), countDeltaToString(i.Status, i.Pods),
+			cpuDeltaToString(i.Status, i.CPURequests), cpuDeltaToString(i.Status, i.CPULimits), cpuDeltaToString(i.Status, i.CPUUsages),
+			memoryDeltaToString(i.Status, i.MemoryRequests), memoryDeltaToString(i.Status, i.MemoryLimits), memoryDeltaToString(i.Status, i.MemoryUsages),
+		})
+	}
+	table.Render()
+}
+
+//countDeltaToString formats a pod count delta
+func countDeltaToString(status string, d kube.CountDelta) string {
+	if status == kube.DiffRemoved {
+		return intToString(d.Before)
+	}
+	if status == kube.DiffAdded || d.Before == d.After {
+		return intToString(d.After)
+	}
+	return fmt.Sprintf("%d (%+d)", d.After, d.After-d.Before)
+}
+
+//cpuDeltaToString formats a cpu delta, the value of the only snapshot of added and removed rows
+func cpuDeltaToString(status string, d kube.CPUDelta) string {
+	if status == kube.DiffRemoved {
+		return d.Before.String()
+	}
+	if !d.Changed() {
+		return d.After.String()
+	}
+	return fmt.Sprintf("%s (%+dm)", d.After.String(), d.After.MilliValue()-d.Before.MilliValue())
+}
+
+//memoryDeltaToString formats a memory delta, the value of the only snapshot of added and removed rows
+func memoryDeltaToString(status string, d kube.MemoryDelta) string {
+	if status == kube.DiffRemoved {
+		return d.Before.String()
+	}
+	if !d.Changed() {
+		return d.After.String()
+	}
+	return fmt.Sprintf("%s (%s)", d.After.String(), bytesDelta(d.After.Value()-d.Before.Value()))
+}
+
+// binaryUnits are the units of bytesDelta from the largest one
+var binaryUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"Gi", 1 << 30},
+	{"Mi", 1 << 20},
+	{"Ki", 1 << 10},
+}
+
+//bytesDelta formats a signed number of bytes in the largest unit it reaches with one decimal at most,
+//e.g. +1.5Mi, or in bytes below 1Ki, e.g. -512B
+func bytesDelta(bytes int64) string {
+	sign, abs := "+", bytes
+	if bytes < 0 {
+		sign, abs = "-", -bytes
+	}
+	for _, unit := range binaryUnits {
+		if abs >= unit.size {
+			value := strconv.FormatFloat(float64(abs)/float64(unit.size), 'f', 1, 64)
+			return sign + strings.TrimSuffix(value, ".0") + unit.suffix
+		}
+	}
+	return sign + strconv.FormatInt(abs, 10) + "B"
+}
+
+//diffStatusColor colours removed red and added yellow
+func diffStatusColor(status string) string {
+	switch status {
+	case kube.DiffRemoved:
+		return redColor(status)
+	case kube.DiffAdded:
+		return yellowColor(status)
+	}
+	return status
+}
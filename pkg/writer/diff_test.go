@@ -0,0 +1,69 @@
+package writer
+
+import (
+	"testing"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+func TestMemoryDeltaToString(t *testing.T) {
+	tests := []struct {
+		status string
+		delta  kube.MemoryDelta
+		want   string
+	}{
+		{kube.DiffChanged, kube.MemoryDelta{Before: kube.NewMemoryResource(1 << 30), After: kube.NewMemoryResource(2 << 30)}, "2048Mi (+1Gi)"},
+		// the delta is computed from the bytes, not from the values shown
+		{kube.DiffChanged, kube.MemoryDelta{Before: kube.NewMemoryResource(1<<20 - 1), After: kube.NewMemoryResource(1<<20 + 1)}, "1Mi (+2B)"},
+		{kube.DiffUnchanged, kube.MemoryDelta{Before: kube.NewMemoryResource(1 << 20), After: kube.NewMemoryResource(1<<20 + 512<<10)}, "1Mi (+512Ki)"},
+		{kube.DiffChanged, kube.MemoryDelta{Before: kube.NewMemoryResource(3 << 20), After: kube.NewMemoryResource(1 << 20)}, "1Mi (-2Mi)"},
+		{kube.DiffChanged, kube.MemoryDelta{Before: nil, After: kube.NewMemoryResource(1 << 20)}, "1Mi"},
+		{kube.DiffUnchanged, kube.MemoryDelta{Before: kube.NewMemoryResource(1 << 20), After: kube.NewMemoryResource(1 << 20)}, "1Mi"},
+		{kube.DiffAdded, kube.MemoryDelta{After: kube.NewMemoryResource(1 << 20)}, "1Mi"},
+		{kube.DiffRemoved, kube.MemoryDelta{Before: kube.NewMemoryResource(1 << 20)}, "1Mi"},
+		{kube.DiffChanged, kube.MemoryDelta{}, kube.NotAvailable},
+	}
+	for _, test := range tests {
+		if got := memoryDeltaToString(test.status, test.delta); got != test.want {
+			t.Errorf("%s %s to %s: got %q, want %q", test.status, test.delta.Before, test.delta.After, got, test.want)
+		}
+	}
+}
+
+func TestBytesDelta(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "+0B"},
+		{1023, "+1023B"},
+		{-1024, "-1Ki"},
+		{1536, "+1.5Ki"},
+		{1<<20 + 1, "+1Mi"},
+		{-(5<<20 + 300<<10), "-5.3Mi"},
+		{3 << 30, "+3Gi"},
+		{10 << 40, "+10240Gi"},
+	}
+	for _, test := range tests {
+		if got := bytesDelta(test.bytes); got != test.want {
+			t.Errorf("bytesDelta(%d): got %q, want %q", test.bytes, got, test.want)
+		}
+	}
+}
+
+func TestCPUDeltaToString(t *testing.T) {
+	tests := []struct {
+		status string
+		delta  kube.CPUDelta
+		want   string
+	}{
+		{kube.DiffChanged, kube.CPUDelta{Before: kube.NewCpuResource(500), After: kube.NewCpuResource(750)}, "750m (+250m)"},
+		{kube.DiffChanged, kube.CPUDelta{Before: kube.NewCpuResource(500), After: nil}, kube.NotAvailable},
+		{kube.DiffRemoved, kube.CPUDelta{Before: kube.NewCpuResource(500)}, "500m"},
+	}
+	for _, test := range tests {
+		if got := cpuDeltaToString(test.status, test.delta); got != test.want {
+			t.Errorf("%s %s to %s: got %q, want %q", test.status, test.delta.Before, test.delta.After, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package writer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+
+	"github.com/logrusorgru/aurora/v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+//Frame is the rendered rows of a table by row key, used to highlight the cells changed in the next frame
+type Frame map[string][]string
+
+//nodeRow formats the columns of a node for the given resource type
+func nodeRow(noderesource kube.NodeAllocatedResources, t string, thresholds kube.Thresholds) []string {
+	switch {
+	case t == "cpu":
+		return []string{
+			noderesource.CPUUsages.String(),
+			newFormat(noderesource.CPURequests.String(), noderesource.CPUCapacity.String()), exceedsCompare(noderesource.CPURequestsFraction, kube.ThresholdCPURequests, thresholds),
+			newFormat(noderesource.CPULimits.String(), noderesource.CPUCapacity.String()), exceedsCompare(noderesource.CPULimitsFraction, kube.ThresholdCPULimits, thresholds),
+		}
+	case t == "memory":
+		return []string{
+			noderesource.MemoryUsages.String(),
+			newFormat(noderesource.MemoryRequests.String(), noderesource.MemoryCapacity.String()), exceedsCompare(noderesource.MemoryRequestsFraction, kube.ThresholdMemRequests, thresholds),
+			newFormat(noderesource.MemoryLimits.String(), noderesource.MemoryCapacity.String()), exceedsCompare(noderesource.MemoryLimitsFraction, kube.ThresholdMemLimits, thresholds),
+		}
+	case t == "gpu":
+		return []string{
+			newFormat(int64ToString(noderesource.NvidiaGpuCountsRequests), int64ToString(noderesource.NvidiaGpuCountsCapacity)), exceedsCompare(noderesource.NvidiaGpuCountsRequestsFraction, kube.ThresholdGPURequests, thresholds),
+			newFormat(int64ToString(noderesource.NvidiaGpuCountsLimits), int64ToString(noderesource.NvidiaGpuCountsCapacity)), exceedsCompare(noderesource.NvidiaGpuCountsLimitsFraction, kube.ThresholdGPULimits, thresholds),
+		}
+	case t == "pod":
+		return []string{
+			newFormat(intToString(noderesource.AllocatedPods), int64ToString(noderesource.PodCapacity)), exceedsCompare(noderesource.PodFraction, kube.ThresholdPods, thresholds),
+		}
+	case t == "ephemeral-storage":
+		s := noderesource.EphemeralStorage
+		return append([]string{s.Usages.String()}, storageRow(s, thresholds)...)
+	case t == "hugepages":
+		return storageRow(noderesource.HugePages, thresholds)
+	case t == "":
+		var row []string
+		for _, t := range []string{"cpu", "memory", "gpu", "pod"} {
+			row = append(row, nodeRow(noderesource, t, thresholds)...)
+		}
+		return row
+	default:
+		e := noderesource.ExtendedResources[t]
+		return []string{
+			newFormat(extendedToString(t, e.Requests), extendedToString(t, e.Capacity)), exceedsCompare(e.RequestsFraction, kube.ThresholdDefault, thresholds),
+			newFormat(extendedToString(t, e.Limits), extendedToString(t, e.Capacity)), exceedsCompare(e.LimitsFraction, kube.ThresholdDefault, thresholds),
+		}
+	}
+}
+
+//podRow formats the columns of a pod for the given resource type
+func podRow(podresource kube.PodAllocatedResources, t string, thresholds kube.Thresholds) []string {
+	switch {
+	case t == "cpu":
+		return []string{
+			podresource.CPUUsages.String(), usageFractionToString(podresource.CPUUsages != nil, podresource.CPUUsagesFraction, kube.ThresholdCPUUsage, thresholds),
+			podresource.CPURequests.String(), podresource.CPULimits.String(),
+		}
+	case t == "memory":
+		return []string{
+			podresource.MemoryUsages.String(), usageFractionToString(podresource.MemoryUsages != nil, podresource.MemoryUsagesFraction, kube.ThresholdMemUsage, thresholds),
+			podresource.MemoryRequests.String(), podresource.MemoryLimits.String(),
+		}
+	case t == "gpu":
+		return []string{
+			int64ToString(podresource.NvidiaGpuCountsRequests), int64ToString(podresource.NvidiaGpuCountsLimits),
+		}
+	case t == "ephemeral-storage":
+		s := podresource.EphemeralStorage
+		return []string{
+			s.Usages.String(), usageFractionToString(s.Usages != nil, s.UsagesFraction, kube.ThresholdDefault, thresholds),
+			s.Requests.String(), s.Limits.String(),
+		}
+	case t == "hugepages":
+		return []string{
+			podresource.HugePages.Requests.String(), podresource.HugePages.Limits.String(),
+		}
+	case t == "":
+		var row []string
+		for _, t := range []string{"cpu", "memory", "gpu"} {
+			row = append(row, podRow(podresource, t, thresholds)...)
+		}
+		return row
+	default:
+		e := podresource.ExtendedResources[t]
+		return []string{
+			extendedToString(t, e.Requests), extendedToString(t, e.Limits),
+		}
+	}
+}
+
+//replicaRow formats the per replica columns of a workload for the given resource type
+func replicaRow(podresource kube.PodAllocatedResources, t string) []string {
+	switch {
+	case t == "cpu":
+		return []string{
+			podresource.CPUUsages.String(), podresource.CPURequests.String(), podresource.CPULimits.String(),
+		}
+	case t == "memory":
+		return []string{
+			podresource.MemoryUsages.String(), podresource.MemoryRequests.String(), podresource.MemoryLimits.String(),
+		}
+	case t == "gpu":
+		return []string{
+			int64ToString(podresource.NvidiaGpuCountsRequests), int64ToString(podresource.NvidiaGpuCountsLimits),
+		}
+	case t == "":
+		var row []string
+		for _, t := range []string{"cpu", "memory", "gpu"} {
+			row = append(row, replicaRow(podresource, t)...)
+		}
+		return row
+	default:
+		e := podresource.ExtendedResources[t]
+		return []string{
+			extendedToString(t, e.Requests), extendedToString(t, e.Limits),
+		}
+	}
+}
+
+//clusterRow formats the row of a cluster for the given resource type
+func clusterRow(cluster kube.ClusterResource, t string, thresholds kube.Thresholds) [][]string {
+	switch {
+	case t == "cpu":
+		return [][]string{{
+			"CPU", cluster.CPUCapacity.String(),
+			cluster.CPURequests.String(), exceedsCompare(cluster.CPURequestsFraction, kube.ThresholdCPURequests, thresholds),
+			cluster.CPULimits.String(), exceedsCompare(cluster.CPULimitsFraction, kube.ThresholdCPULimits, thresholds),
+			cluster.CPUUsages.String(), usageFractionToString(cluster.CPUUsages != nil, cluster.CPUUsagesFraction, kube.ThresholdCPUUsage, thresholds),
+			ratioToString(cluster.CPUOvercommit),
+		}}
+	case t == "memory":
+		return [][]string{{
+			"MEMORY", cluster.MemoryCapacity.String(),
+			cluster.MemoryRequests.String(), exceedsCompare(cluster.MemoryRequestsFraction, kube.ThresholdMemRequests, thresholds),
+			cluster.MemoryLimits.String(), exceedsCompare(cluster.MemoryLimitsFraction, kube.ThresholdMemLimits, thresholds),
+			cluster.MemoryUsages.String(), usageFractionToString(cluster.MemoryUsages != nil, cluster.MemoryUsagesFraction, kube.ThresholdMemUsage, thresholds),
+			ratioToString(cluster.MemoryOvercommit),
+		}}
+	case t == "gpu":
+		return [][]string{{
+			"NVIDIA/GPU", int64ToString(cluster.NvidiaGpuCountsCapacity),
+			int64ToString(cluster.NvidiaGpuCountsRequests), exceedsCompare(cluster.NvidiaGpuCountsRequestsFraction, kube.ThresholdGPURequests, thresholds),
+			int64ToString(cluster.NvidiaGpuCountsLimits), exceedsCompare(cluster.NvidiaGpuCountsLimitsFraction, kube.ThresholdGPULimits, thresholds),
+			"-", "-", "-",
+		}}
+	case t == "pod":
+		return [][]string{{
+			"PODS", int64ToString(cluster.PodCapacity),
+			intToString(cluster.AllocatedPods), exceedsCompare(cluster.PodFraction, kube.ThresholdPods, thresholds),
+			"-", "-", "-", "-", "-",
+		}}
+	case t == "":
+		var rows [][]string
+		for _, t := range []string{"cpu", "memory", "gpu", "pod"} {
+			rows = append(rows, clusterRow(cluster, t, thresholds)...)
+		}
+		return rows
+	default:
+		e := cluster.ExtendedResources[t]
+		return [][]string{{
+			extendedHeader(t), extendedToString(t, e.Capacity),
+			extendedToString(t, e.Requests), exceedsCompare(e.RequestsFraction, kube.ThresholdDefault, thresholds),
+			extendedToString(t, e.Limits), exceedsCompare(e.LimitsFraction, kube.ThresholdDefault, thresholds),
+			"-", "-", "-",
+		}}
+	}
+}
+
+//extendedHeader returns the column name of an extended resource the way NVIDIA/GPU is named,
+//the first label of the domain followed by the name, e.g. AMD/GPU for amd.com/gpu
+func extendedHeader(name string) string {
+	if i := strings.Index(name, "/"); i >= 0 {
+		domain := name[:i]
+		if j := strings.Index(domain, "."); j >= 0 {
+			domain = domain[:j]
+		}
+		name = domain + name[i:]
+	}
+	return strings.ToUpper(name)
+}
+
+//extendedToString formats the quantity of an extended resource, hugepages in binary units
+func extendedToString(name string, value int64) string {
+	if strings.HasPrefix(name, string(v1.ResourceHugePagesPrefix)) {
+		return resource.NewQuantity(value, resource.BinarySI).String()
+	}
+	return int64ToString(value)
+}
+
+//storageRow formats the requests and limits of a storage resource of a node against its capacity
+func storageRow(s kube.StorageResources, thresholds kube.Thresholds) []string {
+	return []string{
+		newFormat(s.Requests.String(), s.Capacity.String()), exceedsCompare(s.RequestsFraction, kube.ThresholdDefault, thresholds),
+		newFormat(s.Limits.String(), s.Capacity.String()), exceedsCompare(s.LimitsFraction, kube.ThresholdDefault, thresholds),
+	}
+}
+
+//usageFractionToString formats the fraction of a usage, which is not available when the usage is unknown
+func usageFractionToString(known bool, f float64, column string, thresholds kube.Thresholds) string {
+	if !known {
+		return kube.NotAvailable
+	}
+	return exceedsCompare(f, column, thresholds)
+}
+
+//containerName marks init containers and sidecars
+func containerName(c kube.ContainerResource) string {
+	if c.Sidecar {
+		return c.Name + " (sidecar)"
+	}
+	if c.Init {
+		return c.Name + " (init)"
+	}
+	return c.Name
+}
+
+//subtotalName names the subtotal row of a group of n nodes
+func subtotalName(n int) string {
+	return fmt.Sprintf("SUBTOTAL (%d nodes)", n)
+}
+
+//newFormat
+func newFormat(a string, b string) string {
+	return fmt.Sprintf("%s/%s", a, b)
+}
+
+//intToString int转string
+func intToString(a int) string {
+	str := strconv.Itoa(a)
+	return str
+}
+
+//float64ToString float64转string
+func float64ToString(s float64) string {
+	return fmt.Sprintf("%v%%", strconv.FormatFloat(s, 'G', -1, 64))
+}
+
+//int64ToString int64转string
+func int64ToString(a int64) string {
+	str := strconv.FormatInt(a, 10)
+	return str
+}
+
+//ratioToString formats a ratio such as the overcommit of a cluster
+func ratioToString(r float64) string {
+	return fmt.Sprintf("%sx", strconv.FormatFloat(r, 'f', 2, 64))
+}
+
+//exceedsCompare formats a percentage, coloured by the warning and critical thresholds of its column
+func exceedsCompare(a float64, column string, thresholds kube.Thresholds) string {
+	switch thresholds.Level(column, a) {
+	case kube.LevelCritical:
+		return redColor(float64ToString(a))
+	case kube.LevelWarning:
+		return yellowColor(float64ToString(a))
+	default:
+		return float64ToString(a)
+	}
+}
+
+//highlightChanges highlights the cells of row that differ from the same cell of previous.
+//Nothing is highlighted without a previous row.
+func highlightChanges(row []string, previous []string) []string {
+	if previous == nil {
+		return row
+	}
+	highlighted := make([]string, len(row))
+	for i := range row {
+		highlighted[i] = row[i]
+		if i < len(previous) && row[i] != previous[i] {
+			highlighted[i] = fmt.Sprintf("%s", aurora.Reverse(row[i]))
+		}
+	}
+	return highlighted
+}
+
+func redColor(s string) string {
+	return fmt.Sprintf("%s", aurora.Red(s))
+}
+
+func yellowColor(s string) string {
+	return fmt.Sprintf("%s", aurora.Yellow(s))
+}
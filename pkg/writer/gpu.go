@@ -0,0 +1,83 @@
+package writer
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+// gpuHeader is the header of GPUWrite and GPUCSVWrite
+var gpuHeader = []string{
+	"NODE", "GPUS", "PRODUCT", "RESOURCE", "SHARING", "PER GPU",
+	"REQ", "REQ(%)", "LIM", "LIM(%)",
+}
+
+//GPUWrite prints the NVIDIA resources of every node, one row per profile under the physical GPUs of the node
+func GPUWrite(out io.Writer, data []kube.NodeGPUResource, thresholds kube.Thresholds, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(gpuHeader)
+	for _, g := range data {
+		node := []string{g.Name, gpuCount(g.GPUs), g.Product}
+		if len(g.Profiles) == 0 {
+			table.Append(append(node, "", "", "", "", "", "", ""))
+			continue
+		}
+		for _, p := range g.Profiles {
+			table.Append(append(node,
+				p.Resource, p.Sharing, perGPU(p.PerGPU),
+				newFormat(int64ToString(p.Requests), int64ToString(p.Capacity)), exceedsCompare(p.RequestsFraction, kube.ThresholdGPURequests, thresholds),
+				newFormat(int64ToString(p.Limits), int64ToString(p.Capacity)), exceedsCompare(p.LimitsFraction, kube.ThresholdGPULimits, thresholds),
+			))
+			// only the first profile of a node shows the node
+			node = []string{"", "", ""}
+		}
+	}
+	table.Render()
+}
+
+//GPUCSVWrite prints the NVIDIA resources of every node as csv or tsv with plain numbers, one row per profile
+func GPUCSVWrite(out io.Writer, data []kube.NodeGPUResource, output string) error {
+	w := csvWriter(out, output)
+	if err := w.Write(csvHeader(append(gpuHeader, "CAPACITY"))); err != nil {
+		return err
+	}
+	for _, g := range data {
+		node := []string{g.Name, int64ToString(g.GPUs), g.Product}
+		if len(g.Profiles) == 0 {
+			if err := w.Write(append(node, "", "", "", "", "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, p := range g.Profiles {
+			row := append(node,
+				p.Resource, p.Sharing, strconv.FormatFloat(p.PerGPU, 'f', -1, 64),
+				int64ToString(p.Requests), fractionToString(p.RequestsFraction),
+				int64ToString(p.Limits), fractionToString(p.LimitsFraction),
+				int64ToString(p.Capacity),
+			)
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//gpuCount formats the number of physical GPUs of a node, which is unknown without the nvidia.com/gpu.count label
+func gpuCount(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return int64ToString(n)
+}
+
+//perGPU formats the capacity of a profile per physical GPU
+func perGPU(f float64) string {
+	if f == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,99 @@
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/kubectl/pkg/cmd/get"
+	"sigs.k8s.io/yaml"
+)
+
+// OutputFormats lists the formats accepted by --output
+var OutputFormats = []string{
+	"json", "yaml", "csv", "tsv",
+	"go-template", "go-template-file", "jsonpath", "jsonpath-file", "jsonpath-as-json",
+	"custom-columns", "custom-columns-file",
+}
+
+//ValidateOutput checks that output is one of OutputFormats and that its template, if any, parses
+func ValidateOutput(output string) error {
+	if output == "json" || output == "yaml" || IsCSVOutput(output) {
+		return nil
+	}
+	_, err := templatePrinter(output)
+	return err
+}
+
+//ObjectWrite prints obj in the given output format
+func ObjectWrite(out io.Writer, obj interface{}, output string) error {
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(obj, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	printer, err := templatePrinter(output)
+	if err != nil {
+		return err
+	}
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(u, out)
+}
+
+//templatePrinter returns the cli-runtime go-template/jsonpath printer or the kubectl custom-columns printer for output
+func templatePrinter(output string) (printers.ResourcePrinter, error) {
+	p, err := genericclioptions.NewKubeTemplatePrintFlags().ToPrinter(output)
+	if !genericclioptions.IsNoCompatiblePrinterError(err) {
+		return p, err
+	}
+	p, err = get.NewCustomColumnsPrintFlags().ToPrinter(output)
+	if genericclioptions.IsNoCompatiblePrinterError(err) {
+		return nil, fmt.Errorf("--output accepts only %s", strings.Join(OutputFormats, ", "))
+	}
+	return p, err
+}
+
+//toUnstructured converts obj through its json form, so the printers see the same fields as -o json.
+//Objects carrying items become an UnstructuredList, which custom-columns prints one row per item.
+func toUnstructured(obj interface{}) (runtime.Object, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	content := map[string]interface{}{}
+	if err := utiljson.Unmarshal(data, &content); err != nil {
+		return nil, err
+	}
+	items, ok := content["items"].([]interface{})
+	if !ok {
+		return &unstructured.Unstructured{Object: content}, nil
+	}
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			list.Items = append(list.Items, unstructured.Unstructured{Object: m})
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,138 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+
+	"sigs.k8s.io/yaml"
+)
+
+// recommendHeader is the header of RecommendWrite
+var recommendHeader = []string{
+	"NAMESPACE", "WORKLOAD", "POD", "CONTAINER",
+	"CPU USE", "CPU REQ", "CPU NEW REQ", "CPU LIM", "CPU NEW LIM", "CPU STATUS",
+	"MEM USE", "MEM REQ", "MEM NEW REQ", "MEM LIM", "MEM NEW LIM", "MEM STATUS",
+}
+
+//RecommendWrite prints the current and recommended requests and limits of every container
+func RecommendWrite(out io.Writer, data []kube.ContainerRecommendation, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(recommendHeader)
+	for _, i := range data {
+		table.Append([]string{
+			i.Namespace, i.WorkloadKind + "/" + i.WorkloadName, i.Pod, i.Container,
+			i.CPU.Usage.String(), i.CPU.Requests.String(), i.CPU.RecommendedRequests.String(),
+			i.CPU.Limits.String(), i.CPU.RecommendedLimits.String(), statusColor(i.CPU.Status),
+			i.Memory.Usage.String(), i.Memory.Requests.String(), i.Memory.RecommendedRequests.String(),
+			i.Memory.Limits.String(), i.Memory.RecommendedLimits.String(), statusColor(i.Memory.Status),
+		})
+	}
+	table.Render()
+}
+
+// patchTargets maps the kinds of workload that can be patched to their api version
+// and to the path of their pod spec.
+var patchTargets = map[string]struct {
+	apiVersion string
+	podSpec    []string
+}{
+	"Deployment":  {"apps/v1", []string{"spec", "template", "spec"}},
+	"StatefulSet": {"apps/v1", []string{"spec", "template", "spec"}},
+	"DaemonSet":   {"apps/v1", []string{"spec", "template", "spec"}},
+	"ReplicaSet":  {"apps/v1", []string{"spec", "template", "spec"}},
+	"Job":         {"batch/v1", []string{"spec", "template", "spec"}},
+	"CronJob":     {"batch/v1", []string{"spec", "jobTemplate", "spec", "template", "spec"}},
+}
+
+//PatchWrite prints a strategic merge patch per workload setting the recommended requests and limits
+//of its containers which are not ok, the sidecars in the init containers. Bare pods cannot be patched
+//and are only listed as comments.
+func PatchWrite(out io.Writer, data []kube.WorkloadRecommendation) error {
+	first := true
+	for _, w := range data {
+		var containers, initContainers []interface{}
+		for _, c := range w.Containers {
+			switch {
+			case c.OK():
+			case c.Sidecar:
+				initContainers = append(initContainers, containerPatch(c))
+			default:
+				containers = append(containers, containerPatch(c))
+			}
+		}
+		if len(containers) == 0 && len(initContainers) == 0 {
+			continue
+		}
+
+		if !first {
+			fmt.Fprintln(out, "---")
+		}
+		first = false
+
+		target, ok := patchTargets[w.Kind]
+		if !ok {
+			fmt.Fprintf(out, "# %s %s/%s cannot be patched, update the resources of its owner instead\n", w.Kind, w.Namespace, w.Name)
+			continue
+		}
+		patch := map[string]interface{}{}
+		if len(containers) > 0 {
+			patch["containers"] = containers
+		}
+		if len(initContainers) > 0 {
+			patch["initContainers"] = initContainers
+		}
+		for i := len(target.podSpec) - 1; i >= 0; i-- {
+			patch = map[string]interface{}{target.podSpec[i]: patch}
+		}
+		patch["apiVersion"] = target.apiVersion
+		patch["kind"] = w.Kind
+		patch["metadata"] = map[string]interface{}{"name": w.Name, "namespace": w.Namespace}
+
+		data, err := yaml.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "# kubectl patch %s %s -n %s --patch-file <file>\n", strings.ToLower(w.Kind), w.Name, w.Namespace)
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//containerPatch returns the resources of the container to patch, only the cpu and memory which are not ok
+//are set, limits are left out when there are none
+func containerPatch(c kube.ContainerRecommendation) map[string]interface{} {
+	requests, limits := map[string]string{}, map[string]string{}
+	if c.CPU.Status != kube.StatusOK {
+		requests["cpu"] = c.CPU.RecommendedRequests.String()
+		if c.CPU.RecommendedLimits.MilliValue() > 0 {
+			limits["cpu"] = c.CPU.RecommendedLimits.String()
+		}
+	}
+	if c.Memory.Status != kube.StatusOK {
+		requests["memory"] = c.Memory.RecommendedRequests.String()
+		if c.Memory.RecommendedLimits.Value() > 0 {
+			limits["memory"] = c.Memory.RecommendedLimits.String()
+		}
+	}
+	resources := map[string]interface{}{"requests": requests}
+	if len(limits) > 0 {
+		resources["limits"] = limits
+	}
+	return map[string]interface{}{"name": c.Container, "resources": resources}
+}
+
+//statusColor colours under-provisioned red and over-provisioned yellow
+func statusColor(status string) string {
+	switch status {
+	case kube.StatusUnderProvisioned:
+		return redColor(status)
+	case kube.StatusOverProvisioned:
+		return yellowColor(status)
+	}
+	return status
+}
@@ -0,0 +1,157 @@
+package writer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+//testContainer returns the recommendation of a container whose cpu and memory have the given statuses
+func testContainer(name, cpuStatus, memoryStatus string) kube.ContainerRecommendation {
+	return kube.ContainerRecommendation{
+		Container: name,
+		CPU: kube.CPURecommendation{
+			RecommendedRequests: kube.NewCpuResource(120),
+			RecommendedLimits:   kube.NewCpuResource(240),
+			Status:              cpuStatus,
+		},
+		Memory: kube.MemoryRecommendation{
+			RecommendedRequests: kube.NewMemoryResource(64 << 20),
+			RecommendedLimits:   kube.NewMemoryResource(0),
+			Status:              memoryStatus,
+		},
+	}
+}
+
+func TestPatchWrite(t *testing.T) {
+	over := testContainer("app", kube.StatusOverProvisioned, kube.StatusOK)
+	tests := []struct {
+		kind string
+		want string
+	}{
+		{"Deployment", `# kubectl patch deployment web -n default --patch-file <file>
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          limits:
+            cpu: 240m
+          requests:
+            cpu: 120m
+`},
+		{"Job", `# kubectl patch job web -n default --patch-file <file>
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: web
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          limits:
+            cpu: 240m
+          requests:
+            cpu: 120m
+`},
+		{"CronJob", `# kubectl patch cronjob web -n default --patch-file <file>
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: web
+  namespace: default
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: app
+            resources:
+              limits:
+                cpu: 240m
+              requests:
+                cpu: 120m
+`},
+		{"Pod", "# Pod default/web cannot be patched, update the resources of its owner instead\n"},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		workload := kube.WorkloadRecommendation{Namespace: "default", Kind: test.kind, Name: "web", Containers: []kube.ContainerRecommendation{over}}
+		if err := PatchWrite(&out, []kube.WorkloadRecommendation{workload}); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.kind, out.String(), test.want)
+		}
+	}
+}
+
+func TestPatchWriteContainers(t *testing.T) {
+	sidecar := testContainer("proxy", kube.StatusOK, kube.StatusUnderProvisioned)
+	sidecar.Sidecar = true
+	workloads := []kube.WorkloadRecommendation{
+		{Namespace: "default", Kind: "StatefulSet", Name: "db", Containers: []kube.ContainerRecommendation{
+			testContainer("db", kube.StatusOK, kube.StatusOK),
+		}},
+		{Namespace: "default", Kind: "DaemonSet", Name: "agent", Containers: []kube.ContainerRecommendation{
+			testContainer("agent", kube.StatusOK, kube.StatusOK),
+			sidecar,
+		}},
+		{Namespace: "default", Kind: "Deployment", Name: "web", Containers: []kube.ContainerRecommendation{
+			testContainer("app", kube.StatusUnderProvisioned, kube.StatusOverProvisioned),
+		}},
+	}
+	var out bytes.Buffer
+	if err := PatchWrite(&out, workloads); err != nil {
+		t.Fatal(err)
+	}
+	want := `# kubectl patch daemonset agent -n default --patch-file <file>
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  namespace: default
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: proxy
+        resources:
+          requests:
+            memory: 64Mi
+---
+# kubectl patch deployment web -n default --patch-file <file>
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          limits:
+            cpu: 240m
+          requests:
+            cpu: 120m
+            memory: 64Mi
+`
+	// the workload whose containers are all ok is left out, a sidecar is patched in the init containers,
+	// and a limit of 0, i.e. none kept with --limit-factor 0, is not set
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,156 @@
+package writer
+
+import (
+	"io"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+//NodeUsageStatsWrite prints the statistics of the usage samples of nodes next to their requests and limits
+func NodeUsageStatsWrite(out io.Writer, data []kube.NodeResource, resourceType []string, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(usageStatsHeader(resourceType, "NODE", "SAMPLES"))
+	for _, i := range data {
+		stats := usageStatsOf(i.UsageStats)
+		row := []string{i.Name, intToString(stats.Samples)}
+		for _, t := range usageStatsTypes(resourceType) {
+			if t == "cpu" {
+				row = append(row, cpuStatsRow(stats.CPU)...)
+				row = append(row, newFormat(i.CPURequests.String(), i.CPUCapacity.String()), newFormat(i.CPULimits.String(), i.CPUCapacity.String()))
+			} else {
+				row = append(row, memoryStatsRow(stats.Memory)...)
+				row = append(row, newFormat(i.MemoryRequests.String(), i.MemoryCapacity.String()), newFormat(i.MemoryLimits.String(), i.MemoryCapacity.String()))
+			}
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+//PodUsageStatsWrite prints the statistics of the usage samples of pods next to their requests and limits
+func PodUsageStatsWrite(out io.Writer, data []kube.PodResource, resourceType []string, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(usageStatsHeader(resourceType, "NAMESPACE", "POD NAME", "SAMPLES"))
+	for _, i := range data {
+		stats := usageStatsOf(i.UsageStats)
+		row := []string{i.Namespace, i.Name, intToString(stats.Samples)}
+		for _, t := range usageStatsTypes(resourceType) {
+			if t == "cpu" {
+				row = append(row, cpuStatsRow(stats.CPU)...)
+				row = append(row, i.CPURequests.String(), i.CPULimits.String())
+			} else {
+				row = append(row, memoryStatsRow(stats.Memory)...)
+				row = append(row, i.MemoryRequests.String(), i.MemoryLimits.String())
+			}
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+//NodeUsageStatsCSVWrite prints the statistics of the usage samples of nodes as csv or tsv with plain numbers
+func NodeUsageStatsCSVWrite(out io.Writer, data []kube.NodeResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	if err := w.Write(csvHeader(usageStatsHeader(resourceType, "NODE", "SAMPLES"))); err != nil {
+		return err
+	}
+	for _, i := range data {
+		stats := usageStatsOf(i.UsageStats)
+		row := []string{i.Name, intToString(stats.Samples)}
+		for _, t := range usageStatsTypes(resourceType) {
+			if t == "cpu" {
+				row = append(row, cpuStatsValues(stats.CPU)...)
+				row = append(row, milliToString(i.CPURequests), milliToString(i.CPULimits))
+			} else {
+				row = append(row, memoryStatsValues(stats.Memory)...)
+				row = append(row, bytesToString(i.MemoryRequests), bytesToString(i.MemoryLimits))
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//PodUsageStatsCSVWrite prints the statistics of the usage samples of pods as csv or tsv with plain numbers
+func PodUsageStatsCSVWrite(out io.Writer, data []kube.PodResource, resourceType []string, output string) error {
+	w := csvWriter(out, output)
+	if err := w.Write(csvHeader(usageStatsHeader(resourceType, "NAMESPACE", "POD NAME", "SAMPLES"))); err != nil {
+		return err
+	}
+	for _, i := range data {
+		stats := usageStatsOf(i.UsageStats)
+		row := []string{i.Namespace, i.Name, intToString(stats.Samples)}
+		for _, t := range usageStatsTypes(resourceType) {
+			if t == "cpu" {
+				row = append(row, cpuStatsValues(stats.CPU)...)
+				row = append(row, milliToString(i.CPURequests), milliToString(i.CPULimits))
+			} else {
+				row = append(row, memoryStatsValues(stats.Memory)...)
+				row = append(row, bytesToString(i.MemoryRequests), bytesToString(i.MemoryLimits))
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+//usageStatsTypes returns the types of resourceType that are sampled, cpu and memory for all types
+func usageStatsTypes(resourceType []string) []string {
+	var types []string
+	for _, t := range []string{"cpu", "memory"} {
+		for _, r := range resourceType {
+			if r == t || r == "" {
+				types = append(types, t)
+				break
+			}
+		}
+	}
+	return types
+}
+
+//usageStatsHeader returns the columns of the usage statistics of the sampled types
+func usageStatsHeader(resourceType []string, leading ...string) []string {
+	header := append([]string{}, leading...)
+	for _, t := range usageStatsTypes(resourceType) {
+		name := "CPU"
+		if t == "memory" {
+			name = "MEM"
+		}
+		header = append(header, name+" MIN", name+" AVG", name+" P95", name+" MAX", name+" REQ", name+" LIM")
+	}
+	return header
+}
+
+//usageStatsOf returns the statistics of a node or pod, with unknown values if it was not sampled
+func usageStatsOf(stats *kube.UsageStats) kube.UsageStats {
+	if stats == nil {
+		return kube.UsageStats{}
+	}
+	return *stats
+}
+
+//cpuStatsRow formats the cpu usage statistics
+func cpuStatsRow(s kube.CPUUsageStats) []string {
+	return []string{s.Min.String(), s.Avg.String(), s.P95.String(), s.Max.String()}
+}
+
+//memoryStatsRow formats the memory usage statistics
+func memoryStatsRow(s kube.MemoryUsageStats) []string {
+	return []string{s.Min.String(), s.Avg.String(), s.P95.String(), s.Max.String()}
+}
+
+//cpuStatsValues returns the raw values of cpuStatsRow
+func cpuStatsValues(s kube.CPUUsageStats) []string {
+	return []string{milliToString(s.Min), milliToString(s.Avg), milliToString(s.P95), milliToString(s.Max)}
+}
+
+//memoryStatsValues returns the raw values of memoryStatsRow
+func memoryStatsValues(s kube.MemoryUsageStats) []string {
+	return []string{bytesToString(s.Min), bytesToString(s.Avg), bytesToString(s.P95), bytesToString(s.Max)}
+}
@@ -1,18 +1,168 @@
 package writer
 
 import (
-	"os"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
 
 	"github.com/olekukonko/tablewriter"
 )
 
-//NodeWrite
-func NodeWrite(data [][]string, resourceType []string, outType bool) {
-	//var table *tablewriter.Table
+//NodeWrite prints nodes and returns the number of lines above the first node, the header of the table
+func NodeWrite(out io.Writer, data []kube.NodeResource, resourceType []string, thresholds kube.Thresholds, outType bool) int {
+	NodeWatchWrite(out, data, nil, resourceType, thresholds, outType)
+	return headerHeight(nodeHeader(resourceType, "NODE"), outType)
+}
+
+//NodeWatchWrite prints nodes like NodeWrite, highlighting the cells changed since the previous frame.
+//It returns the frame to pass to the next call.
+func NodeWatchWrite(out io.Writer, data []kube.NodeResource, previous Frame, resourceType []string, thresholds kube.Thresholds, outType bool) Frame {
+	frame := make(Frame)
+	table := table(out, outType)
+	table.SetHeader(nodeHeader(resourceType, "NODE"))
+	for _, i := range data {
+		row := []string{i.Name}
+		for _, t := range resourceType {
+			row = append(row, nodeRow(i.NodeAllocatedResources, t, thresholds)...)
+		}
+		frame[i.Name] = row
+		table.Append(highlightChanges(row, previous[i.Name]))
+	}
+	table.Render()
+	return frame
+}
+
+//NodeGroupWrite prints the nodes of every group followed by the subtotal of the group
+func NodeGroupWrite(out io.Writer, data []kube.NodeGroupResource, label string, resourceType []string, thresholds kube.Thresholds, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(nodeHeader(resourceType, "GROUP", "NODE"))
+	for _, g := range data {
+		for _, i := range g.Nodes {
+			row := []string{g.Value, i.Name}
+			for _, t := range resourceType {
+				row = append(row, nodeRow(i.NodeAllocatedResources, t, thresholds)...)
+			}
+			table.Append(row)
+		}
+		row := []string{g.Value, subtotalName(g.Total.Nodes)}
+		for _, t := range resourceType {
+			row = append(row, nodeRow(g.Total.NodeAllocatedResources, t, thresholds)...)
+		}
+		table.Append(row)
+	}
+	if !outType {
+		table.SetCaption(true, fmt.Sprintf("Grouped by label %s.", label))
+	}
+	table.Render()
+}
+
+//PodWrite prints pods and returns the number of lines above the first pod, the header of the table
+func PodWrite(out io.Writer, data []kube.PodResource, resourceType []string, thresholds kube.Thresholds, outType bool) int {
+	PodWatchWrite(out, data, nil, resourceType, thresholds, outType)
+	return headerHeight(podHeader(resourceType, "NAMESPACE", "POD NAME"), outType)
+}
 
-	table := table(outType)
+//PodWatchWrite prints pods like PodWrite, highlighting the cells changed since the previous frame.
+//It returns the frame to pass to the next call.
+func PodWatchWrite(out io.Writer, data []kube.PodResource, previous Frame, resourceType []string, thresholds kube.Thresholds, outType bool) Frame {
+	frame := make(Frame)
+	table := table(out, outType)
+	table.SetHeader(podHeader(resourceType, "NAMESPACE", "POD NAME"))
+	for _, i := range data {
+		row := []string{i.Namespace, i.Name}
+		for _, t := range resourceType {
+			row = append(row, podRow(i.PodAllocatedResources, t, thresholds)...)
+		}
+		key := i.Namespace + "/" + i.Name
+		frame[key] = row
+		table.Append(highlightChanges(row, previous[key]))
+	}
+	table.Render()
+	return frame
+}
+
+//ContainerWrite
+func ContainerWrite(out io.Writer, data []kube.PodResource, resourceType []string, thresholds kube.Thresholds, outType bool) {
+	ContainerWatchWrite(out, data, nil, resourceType, thresholds, outType)
+}
+
+//ContainerWatchWrite prints containers like ContainerWrite, highlighting the cells changed since the previous frame.
+//It returns the frame to pass to the next call.
+func ContainerWatchWrite(out io.Writer, data []kube.PodResource, previous Frame, resourceType []string, thresholds kube.Thresholds, outType bool) Frame {
+	frame := make(Frame)
+	table := table(out, outType)
+	table.SetHeader(podHeader(resourceType, "NAMESPACE", "POD NAME", "CONTAINER"))
+	for _, i := range data {
+		for _, c := range i.Containers {
+			row := []string{i.Namespace, i.Name, containerName(c)}
+			for _, t := range resourceType {
+				row = append(row, podRow(c.PodAllocatedResources, t, thresholds)...)
+			}
+			key := i.Namespace + "/" + i.Name + "/" + containerName(c)
+			frame[key] = row
+			table.Append(highlightChanges(row, previous[key]))
+		}
+	}
+	table.Render()
+	return frame
+}
+
+//NamespaceWrite
+func NamespaceWrite(out io.Writer, data []kube.NamespaceResource, resourceType []string, thresholds kube.Thresholds, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(podHeader(resourceType, "NAMESPACE", "PODS"))
+	for _, i := range data {
+		row := []string{i.Name, intToString(i.Pods)}
+		for _, t := range resourceType {
+			row = append(row, podRow(i.PodAllocatedResources, t, thresholds)...)
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+//WorkloadWrite
+func WorkloadWrite(out io.Writer, data []kube.WorkloadResource, resourceType []string, thresholds kube.Thresholds, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(append(podHeader(resourceType, "NAMESPACE", "KIND", "WORKLOAD", "REPLICAS"), replicaHeader(resourceType)...))
+	for _, i := range data {
+		row := []string{i.Namespace, i.Kind, i.Name, intToString(i.Replicas)}
+		for _, t := range resourceType {
+			row = append(row, podRow(i.PodAllocatedResources, t, thresholds)...)
+		}
+		for _, t := range resourceType {
+			row = append(row, replicaRow(i.PerReplica, t)...)
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+//ClusterWrite
+func ClusterWrite(out io.Writer, data kube.ClusterResource, resourceType []string, thresholds kube.Thresholds, outType bool) {
+	table := table(out, outType)
+	table.SetHeader(clusterHeader)
+	for _, t := range resourceType {
+		table.AppendBulk(clusterRow(data, t, thresholds))
+	}
+	if !outType {
+		table.SetCaption(true, fmt.Sprintf("Summed up over %d nodes.", data.Nodes))
+	}
+	table.Render()
+}
+
+//clusterHeader
+var clusterHeader = []string{
+	"RESOURCE", "CAPACITY", "REQUESTS", "REQ(%)", "LIMITS", "LIM(%)", "USAGE", "USE(%)", "OVERCOMMIT",
+}
+
+//nodeHeader returns the given leading columns followed by the node resource columns
+func nodeHeader(resourceType []string, leading ...string) []string {
 	var header []string
-	header = append(header, "NODE")
+	header = append(header, leading...)
 	for _, t := range resourceType {
 		switch {
 		case t == "cpu":
@@ -32,31 +182,36 @@ func NodeWrite(data [][]string, resourceType []string, outType bool) {
 			header = append(header,
 				"Pod Capacity", "Pod(%)",
 			)
-		default:
+		case t == "ephemeral-storage":
+			header = append(header,
+				"EPH-STORAGE USE", "EPH-STORAGE REQ", "EPH-STORAGE REQ(%)", "EPH-STORAGE LIM", "EPH-STORAGE LIM(%)",
+			)
+		case t == "hugepages":
+			header = append(header,
+				"HUGEPAGES REQ", "HUGEPAGES REQ(%)", "HUGEPAGES LIM", "HUGEPAGES LIM(%)",
+			)
+		case t == "":
 			header = append(header,
 				"CPU USE", "CPU REQ", "CPU REQ(%)", "CPU LIM", "CPU LIM(%)",
 				"MEM USE", "MEM REQ", "MEM REQ(%)", "MEM LIM", "MEM LIM(%)",
 				"NVIDIA/GPU REQ", "NVIDIA/GPU REQ(%)", "NVIDIA/GPU LIM", "NVIDIA/GPU LIM(%)",
 				// "ALIYUN/GPU-MEM REQ", "ALIYUN/GPU-MEM REQ(%)", "ALIYUN/GPU-MEM LIM", "ALIYUN/GPU-MEM LIM(%)",
-				"PodCount (%)",
+				"PodCount", "PodCount(%)",
+			)
+		default:
+			name := extendedHeader(t)
+			header = append(header,
+				name+" REQ", name+" REQ(%)", name+" LIM", name+" LIM(%)",
 			)
 		}
 	}
-	table.SetHeader(header)
-	for _, i := range data {
-		table.Append(i)
-	}
-	table.Render()
-
+	return header
 }
 
-//PodWrite
-func PodWrite(data [][]string, resourceType []string, outType bool) {
-	//var table *tablewriter.Table
-
-	table := table(outType)
+//podHeader returns the given leading columns followed by the pod resource columns
+func podHeader(resourceType []string, leading ...string) []string {
 	var header []string
-	header = append(header, "NAMESPACE", "POD NAME")
+	header = append(header, leading...)
 
 	for _, t := range resourceType {
 		switch {
@@ -73,26 +228,82 @@ func PodWrite(data [][]string, resourceType []string, outType bool) {
 				"NVIDIA/GPU REQ", "NVIDIA/GPU LIM",
 				// "ALIYUN/GPU-MEM REQ", "ALIYUN/GPU-MEM LIM",
 			)
-		default:
+		case t == "ephemeral-storage":
+			header = append(header,
+				"EPH-STORAGE USE", "EPH-STORAGE USE(%)", "EPH-STORAGE REQ", "EPH-STORAGE LIM",
+			)
+		case t == "hugepages":
+			header = append(header,
+				"HUGEPAGES REQ", "HUGEPAGES LIM",
+			)
+		case t == "":
 			header = append(header,
 				"CPU USE ", "CPU USE(%)", "CPU REQ", "CPU LIM",
 				"MEM USE", "MEM USE(%)", "MEM REQ", "MEM LIM",
 				"NVIDIA/GPU REQ", "NVIDIA/GPU LIM",
 				// "ALIYUN/GPU-MEM REQ", "ALIYUN/GPU-MEM LIM",
 			)
+		default:
+			name := extendedHeader(t)
+			header = append(header,
+				name+" REQ", name+" LIM",
+			)
 		}
 	}
-	table.SetHeader(header)
-	for _, i := range data {
-		table.Append(i)
+	return header
+}
+
+//replicaHeader returns the per replica columns of the workload view
+func replicaHeader(resourceType []string) []string {
+	var header []string
+	for _, t := range resourceType {
+		switch {
+		case t == "cpu":
+			header = append(header,
+				"CPU USE/REPLICA", "CPU REQ/REPLICA", "CPU LIM/REPLICA",
+			)
+		case t == "memory":
+			header = append(header,
+				"MEM USE/REPLICA", "MEM REQ/REPLICA", "MEM LIM/REPLICA",
+			)
+		case t == "gpu":
+			header = append(header,
+				"NVIDIA/GPU REQ/REPLICA", "NVIDIA/GPU LIM/REPLICA",
+			)
+		case t == "":
+			header = append(header,
+				"CPU USE/REPLICA", "CPU REQ/REPLICA", "CPU LIM/REPLICA",
+				"MEM USE/REPLICA", "MEM REQ/REPLICA", "MEM LIM/REPLICA",
+				"NVIDIA/GPU REQ/REPLICA", "NVIDIA/GPU LIM/REPLICA",
+			)
+		default:
+			name := extendedHeader(t)
+			header = append(header,
+				name+" REQ/REPLICA", name+" LIM/REPLICA",
+			)
+		}
 	}
-	table.Render()
+	return header
+}
 
+//headerHeight returns the number of lines of the header of a table, its borders included, rendering it
+//without rows as the header may be wrapped
+func headerHeight(header []string, outType bool) int {
+	var buf bytes.Buffer
+	table := table(&buf, outType)
+	table.SetHeader(header)
+	table.Render()
+	height := strings.Count(buf.String(), "\n")
+	if !outType {
+		// the bottom border is below the rows
+		height--
+	}
+	return height
 }
 
 //table
-func table(outType bool) *tablewriter.Table {
-	table := tablewriter.NewWriter(os.Stdout)
+func table(out io.Writer, outType bool) *tablewriter.Table {
+	table := tablewriter.NewWriter(out)
 	if outType {
 		table.SetAutoWrapText(false)
 		table.SetAutoFormatHeaders(true)
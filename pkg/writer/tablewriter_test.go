@@ -0,0 +1,31 @@
+package writer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bryant-rh/kubectl-resource-view/pkg/kube"
+)
+
+func TestWriteHeaderHeight(t *testing.T) {
+	nodes := []kube.NodeResource{{Name: "node-a"}, {Name: "node-b"}}
+	pods := []kube.PodResource{{Namespace: "default", Name: "pod-a"}, {Namespace: "default", Name: "pod-b"}}
+	for _, outType := range []bool{false, true} {
+		for _, resourceType := range [][]string{{""}, {"cpu"}, {"memory", "pod"}} {
+			var out bytes.Buffer
+			height := NodeWrite(&out, nodes, resourceType, kube.DefaultThresholds(), outType)
+			lines := strings.Split(out.String(), "\n")
+			if len(lines) < height+2 || !strings.Contains(lines[height], "node-a") || !strings.Contains(lines[height+1], "node-b") {
+				t.Errorf("%v no format %v: got the nodes below line %d of\n%s", resourceType, outType, height, out.String())
+			}
+		}
+
+		var out bytes.Buffer
+		height := PodWrite(&out, pods, []string{"cpu", "memory"}, kube.DefaultThresholds(), outType)
+		lines := strings.Split(out.String(), "\n")
+		if len(lines) < height+2 || !strings.Contains(lines[height], "pod-a") || !strings.Contains(lines[height+1], "pod-b") {
+			t.Errorf("no format %v: got the pods below line %d of\n%s", outType, height, out.String())
+		}
+	}
+}